@@ -0,0 +1,150 @@
+package witcharcana
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MaxMightSnapshots is the most might snapshots kept for each player. The oldest are dropped first.
+const MaxMightSnapshots = 10
+
+// MightSnapshot records a player's might at the time it was recorded.
+type MightSnapshot struct {
+	At    time.Time `json:"at"`
+	Might int64     `json:"might"`
+}
+
+// touch records that the player was seen, and when changed is true that their stats changed. A
+// snapshot of their might is kept when it was recorded. Nothing is recorded without a clock.
+func (cs *Clubs) touch(p *Player, changed, might bool) {
+	if cs.now == nil {
+		return
+	}
+
+	t := cs.now().UTC()
+	seen := t
+	p.LastSeen = &seen
+	if changed {
+		updated := t
+		p.UpdatedAt = &updated
+	}
+	if might && p.Might > 0 {
+		// the full slice expression stops appends writing into a copied player's snapshots.
+		ss := append(p.Snapshots[:len(p.Snapshots):len(p.Snapshots)], &MightSnapshot{At: t, Might: p.Might})
+		if len(ss) > MaxMightSnapshots {
+			ss = ss[len(ss)-MaxMightSnapshots:]
+		}
+		p.Snapshots = ss
+	}
+}
+
+// InactivityOptions chooses which players are reported as inactive. Either check is skipped when
+// left at 0.
+type InactivityOptions struct {
+	// Days reports players whose stats haven't changed in at least this many days.
+	Days int
+	// Snapshots reports players whose might hasn't grown across this many of their latest snapshots.
+	Snapshots int
+	// Club limits the report to a single club.
+	Club string
+}
+
+// InactivityReport lists the players that look inactive.
+type InactivityReport []*InactivePlayer
+
+// InactivePlayer is a player that looks inactive and the reasons why.
+type InactivePlayer struct {
+	*Player
+	Reasons []string `json:"reasons"`
+}
+
+// InactivityReport returns the players matching any of the checks in opts, ordered by club name.
+// Players already marked inactive are left out, as are players without the history a check needs.
+func (cs *Clubs) InactivityReport(ctx context.Context, opts *InactivityOptions) (InactivityReport, error) {
+	if opts.Days < 0 {
+		return nil, fmt.Errorf("days cannot be negative. got %d", opts.Days)
+	}
+	if opts.Snapshots < 0 || opts.Snapshots == 1 {
+		return nil, fmt.Errorf("snapshots must be at least 2 to compare might. got %d", opts.Snapshots)
+	}
+
+	clubs, err := selectClubs(ctx, cs, opts.Club)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if cs.now != nil {
+		now = cs.now()
+	}
+
+	report := InactivityReport{}
+	for _, c := range clubs {
+		for _, p := range c.Players {
+			if p.Inactive {
+				continue
+			}
+
+			reasons := inactivity(p, opts, now)
+			if len(reasons) == 0 {
+				continue
+			}
+
+			rp := *p
+			rp.Club = c.Name
+			report = append(report, &InactivePlayer{Player: &rp, Reasons: reasons})
+		}
+	}
+
+	return report, nil
+}
+
+// inactivity returns the reasons the player looks inactive at the time given.
+func inactivity(p *Player, opts *InactivityOptions, now time.Time) []string {
+	var reasons []string
+
+	if opts.Days > 0 && p.UpdatedAt != nil {
+		if days := int(now.Sub(*p.UpdatedAt).Hours() / 24); days >= opts.Days {
+			reasons = append(reasons, fmt.Sprintf("no changes in %d days", days))
+		}
+	}
+
+	if n := opts.Snapshots; n > 0 && len(p.Snapshots) >= n {
+		first, last := p.Snapshots[len(p.Snapshots)-n], p.Snapshots[len(p.Snapshots)-1]
+		if last.Might <= first.Might {
+			reasons = append(reasons, fmt.Sprintf("might hasn't grown across the last %d snapshots", n))
+		}
+	}
+
+	return reasons
+}
+
+// MarkInactive marks the named players as inactive and returns the changes made. Players already
+// marked inactive are left as they are.
+func (cs *Clubs) MarkInactive(ctx context.Context, names ...string) (*ChangeSet, error) {
+	chs := &ChangeSet{}
+
+	for _, name := range names {
+		n, _, c, err := playerClub(ctx, cs, name)
+		if err != nil {
+			return nil, fmt.Errorf("marking inactive: %w", err)
+		}
+		if c.Players[n].Inactive {
+			continue
+		}
+
+		c.Players[n].Inactive = true
+		chs.Updated = append(chs.Updated, &PlayerChange{Name: name, Club: c.Name, Fields: []FieldChange{
+			{Field: "inactive", Old: "false", New: "true"},
+		}})
+
+		if cs.db != nil {
+			if err := cs.db.Update(ctx, c); err != nil {
+				return nil, fmt.Errorf("updating db: %w", err)
+			}
+		}
+	}
+
+	return chs, nil
+}
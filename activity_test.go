@@ -0,0 +1,244 @@
+package witcharcana
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func testTime(daysAgo int) *time.Time {
+	t := testNow.AddDate(0, 0, -daysAgo)
+	return &t
+}
+
+func testSnapshots(mights ...int64) []*MightSnapshot {
+	ss := make([]*MightSnapshot, len(mights))
+	for i, m := range mights {
+		ss[i] = &MightSnapshot{At: testNow.AddDate(0, 0, i-len(mights)), Might: m}
+	}
+
+	return ss
+}
+
+func TestPlayersTouched(t *testing.T) {
+	ctx := context.Background()
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"AZA": {Name: "AZA", Players: Players{
+				{Name: "Fayeee", Level: 18, Might: 70000000, UpdatedAt: testTime(30), Snapshots: testSnapshots(70000000)},
+			}},
+		},
+		now: func() time.Time { return testNow },
+	}
+
+	p, err := cs.CreatePlayer(ctx, "AZA", &Player{Name: "Quinoa", Might: 850000})
+	assert.NoError(t, err)
+	assert.Equal(t, testNow, *p.LastSeen)
+	assert.Equal(t, testNow, *p.UpdatedAt)
+	assert.Equal(t, []*MightSnapshot{{At: testNow, Might: 850000}}, p.Snapshots)
+
+	// seen without any changes keeps when the player last changed
+	p, err = cs.UpdatePlayer(ctx, &Player{Name: "Fayeee", Level: 18})
+	assert.NoError(t, err)
+	assert.Equal(t, testNow, *p.LastSeen)
+	assert.Equal(t, *testTime(30), *p.UpdatedAt)
+	assert.Len(t, p.Snapshots, 1)
+
+	// might recorded again is snapshot even when unchanged
+	_, err = cs.BulkUpdatePlayers(ctx, Players{{Name: "Fayeee", Club: "AZA", Might: 70000000}}, SyncNone)
+	assert.NoError(t, err)
+	p = cs.clubs["AZA"].Players[0]
+	assert.Equal(t, *testTime(30), *p.UpdatedAt)
+	assert.Equal(t, int64(70000000), p.Snapshots[1].Might)
+
+	_, err = cs.BulkUpdatePlayers(ctx, Players{{Name: "Fayeee", Club: "AZA", Level: 19}}, SyncNone)
+	assert.NoError(t, err)
+	p = cs.clubs["AZA"].Players[0]
+	assert.Equal(t, testNow, *p.UpdatedAt)
+	assert.Len(t, p.Snapshots, 2)
+}
+
+func TestPlayersTouchedByEdits(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name string
+		edit func(cs *Clubs) error
+		seen bool
+	}{
+		{name: "moved", edit: func(cs *Clubs) error { _, err := cs.MovePlayer(ctx, "Fayeee", "SP"); return err }, seen: true},
+		{name: "tagged", edit: func(cs *Clubs) error { _, err := cs.TagPlayer(ctx, "Fayeee", "rally"); return err }, seen: true},
+		{name: "notes", edit: func(cs *Clubs) error { _, err := cs.SetPlayerNotes(ctx, "Fayeee", "on vacation"); return err }, seen: true},
+		{name: "role", edit: func(cs *Clubs) error { _, err := cs.SetRole(ctx, "Fayeee", RoleOfficer); return err }, seen: true},
+		// players flagged inactive or missing from an import weren't seen
+		{name: "marked inactive", edit: func(cs *Clubs) error { _, err := cs.MarkInactive(ctx, "Fayeee"); return err }},
+		{name: "synced inactive", edit: func(cs *Clubs) error {
+			_, err := cs.BulkUpdatePlayers(ctx, Players{{Name: "Richard", Club: "AZA"}}, SyncInactive)
+			return err
+		}},
+		{name: "synced unassigned", edit: func(cs *Clubs) error {
+			_, err := cs.BulkUpdatePlayers(ctx, Players{{Name: "Richard", Club: "AZA"}}, SyncUnassign)
+			return err
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{
+						{Name: "Fayeee", Level: 18, LastSeen: testTime(30), UpdatedAt: testTime(30)},
+						{Name: "Richard", LastSeen: testTime(30), UpdatedAt: testTime(30)},
+					}},
+					"SP": {Name: "SP"},
+				},
+				now: func() time.Time { return testNow },
+			}
+
+			assert.NoError(t, tc.edit(cs))
+
+			_, p := player(ctx, cs, "Fayeee")
+			if tc.seen {
+				assert.Equal(t, testNow, *p.LastSeen)
+			} else {
+				assert.Equal(t, *testTime(30), *p.LastSeen)
+			}
+			assert.Equal(t, *testTime(30), *p.UpdatedAt)
+		})
+	}
+}
+
+func TestTouchKeepsLatestSnapshots(t *testing.T) {
+	cs := &Clubs{now: func() time.Time { return testNow }}
+	p := &Player{Name: "Fayeee", Might: 5, Snapshots: testSnapshots(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)}
+	cp := *p
+
+	cs.touch(p, true, true)
+
+	assert.Len(t, p.Snapshots, MaxMightSnapshots)
+	assert.Equal(t, int64(2), p.Snapshots[0].Might)
+	assert.Equal(t, &MightSnapshot{At: testNow, Might: 5}, p.Snapshots[MaxMightSnapshots-1])
+	// copies of the player keep their own snapshots
+	assert.Equal(t, int64(10), cp.Snapshots[MaxMightSnapshots-1].Might)
+
+	// nothing is recorded without a clock
+	p = &Player{Name: "Quinoa", Might: 5}
+	(&Clubs{}).touch(p, true, true)
+	assert.Equal(t, &Player{Name: "Quinoa", Might: 5}, p)
+}
+
+func TestCsInactivityReport(t *testing.T) {
+	newClubs := func() *Clubs {
+		return &Clubs{
+			clubs: map[string]*Club{
+				"AZA": {Name: "AZA", Players: Players{
+					{Name: "Fayeee", UpdatedAt: testTime(2), Snapshots: testSnapshots(60000000, 70000000)},
+					{Name: "Richard", UpdatedAt: testTime(40), Inactive: true},
+					{Name: "LoverOnyx", UpdatedAt: testTime(21), Snapshots: testSnapshots(50000000, 52000000, 51000000, 52000000)},
+				}},
+				"SP": {Name: "SP", Players: Players{
+					{Name: "Quinoa", UpdatedAt: testTime(3), Snapshots: testSnapshots(850000, 850000)},
+					{Name: "Hoeb"},
+				}},
+			},
+			now: func() time.Time { return testNow },
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		opts        *InactivityOptions
+		expected    map[string][]string
+		expectedErr error
+	}{
+		{
+			name:     "stale for days",
+			opts:     &InactivityOptions{Days: 14},
+			expected: map[string][]string{"LoverOnyx": {"no changes in 21 days"}},
+		},
+		{
+			name:     "might flat across snapshots",
+			opts:     &InactivityOptions{Snapshots: 2},
+			expected: map[string][]string{"Quinoa": {"might hasn't grown across the last 2 snapshots"}},
+		},
+		{
+			name: "both",
+			opts: &InactivityOptions{Days: 21, Snapshots: 3},
+			expected: map[string][]string{
+				"LoverOnyx": {"no changes in 21 days", "might hasn't grown across the last 3 snapshots"},
+			},
+		},
+		{
+			name:     "single club",
+			opts:     &InactivityOptions{Snapshots: 2, Club: "SP"},
+			expected: map[string][]string{"Quinoa": {"might hasn't grown across the last 2 snapshots"}},
+		},
+		{
+			name:     "no checks",
+			opts:     &InactivityOptions{},
+			expected: map[string][]string{},
+		},
+		{
+			name:        "one snapshot",
+			opts:        &InactivityOptions{Snapshots: 1},
+			expectedErr: fmt.Errorf("snapshots must be at least 2 to compare might. got 1"),
+		},
+		{
+			name:        "club not found",
+			opts:        &InactivityOptions{Days: 1, Club: "404"},
+			expectedErr: fmt.Errorf(`getting club: club not found: "404"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := newClubs().InactivityReport(context.Background(), tc.opts)
+
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+
+			reasons := map[string][]string{}
+			for _, ip := range actual {
+				reasons[ip.Name] = ip.Reasons
+			}
+			assert.Equal(t, tc.expected, reasons)
+		})
+	}
+}
+
+func TestCsMarkInactive(t *testing.T) {
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee"}, {Name: "Richard", Inactive: true}}},
+		},
+	}
+
+	chs, err := cs.MarkInactive(context.Background(), "Fayeee", "Richard")
+	assert.NoError(t, err)
+	assert.Equal(t, &ChangeSet{Updated: []*PlayerChange{
+		{Name: "Fayeee", Club: "AZA", Fields: []FieldChange{{Field: "inactive", Old: "false", New: "true"}}},
+	}}, chs)
+	assert.True(t, cs.clubs["AZA"].Players[0].Inactive)
+
+	_, err = cs.MarkInactive(context.Background(), "Hoeb")
+	assert.EqualError(t, err, `marking inactive: player not found: "Hoeb"`)
+}
+
+func TestInactiveTemplate(t *testing.T) {
+	var b bytes.Buffer
+	err := NewTemplates("").Render(&b, InactivityReport{
+		{Player: &Player{Name: "LoverOnyx", Club: "AZA"}, Reasons: []string{"no changes in 21 days", "might hasn't grown across the last 3 snapshots"}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Inactive players:\n"+
+		"  LoverOnyx            AZA   no changes in 21 days, might hasn't grown across the last 3 snapshots\n", b.String())
+}
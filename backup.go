@@ -0,0 +1,187 @@
+package witcharcana
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxBackups is how many backups of a data file are kept before the oldest are removed.
+	maxBackups = 10
+	// backupTimeFormat sorts lexically in the same order as chronologically.
+	backupTimeFormat = "20060102T150405.000000000Z"
+)
+
+// Backup is a copy of the data file taken before it was overwritten.
+type Backup struct {
+	Name string    `json:"name"`
+	Path string    `json:"path"`
+	Time time.Time `json:"time"`
+}
+
+// BackupDir returns the directory backups of the data file at loc are kept in.
+func BackupDir(loc string) string {
+	return filepath.Join(filepath.Dir(loc), ".backups")
+}
+
+// Backups lists the backups of the data file at loc, newest first.
+func Backups(loc string) ([]*Backup, error) {
+	dir := BackupDir(loc)
+	prefix, ext := backupPrefix(loc)
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*Backup{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading backup dir: %w", err)
+	}
+
+	bs := []*Backup{}
+	for _, e := range entries {
+		n := e.Name()
+		if e.IsDir() || !strings.HasPrefix(n, prefix) || !strings.HasSuffix(n, ext) {
+			continue
+		}
+
+		t, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(n, prefix), ext))
+		if err != nil {
+			continue
+		}
+
+		bs = append(bs, &Backup{Name: n, Path: filepath.Join(dir, n), Time: t})
+	}
+
+	sort.Slice(bs, func(i, j int) bool { return bs[i].Time.After(bs[j].Time) })
+
+	return bs, nil
+}
+
+// RestoreBackup replaces the data file at loc with the named backup. The data file being replaced is
+// backed up first so a restore can itself be undone.
+func RestoreBackup(loc, name string) error {
+	bs, err := Backups(loc)
+	if err != nil {
+		return fmt.Errorf("listing backups: %w", err)
+	}
+
+	var b *Backup
+	for _, bk := range bs {
+		if bk.Name == name {
+			b = bk
+			break
+		}
+	}
+	if b == nil {
+		return fmt.Errorf("backup %q: %w", name, fs.ErrNotExist)
+	}
+
+	dat, err := os.ReadFile(b.Path)
+	if err != nil {
+		return fmt.Errorf("reading backup: %w", err)
+	}
+	if _, err := decodeDataFile(dat); err != nil {
+		return fmt.Errorf("backup %q is invalid: %w", name, err)
+	}
+
+	if err := backup(loc); err != nil {
+		return fmt.Errorf("backing up current data: %w", err)
+	}
+	if err := writeFileAtomic(loc, dat, 0644); err != nil {
+		return fmt.Errorf("writing: %w", err)
+	}
+
+	return nil
+}
+
+// backupPrefix returns the file name prefix and extension used for backups of the file at loc.
+func backupPrefix(loc string) (string, string) {
+	base := filepath.Base(loc)
+	ext := filepath.Ext(base)
+
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// backup copies the data file at loc into the backup dir, removing the oldest backups once there are
+// more than maxBackups. Nothing is done when there's no file at loc yet or it's empty.
+func backup(loc string) error {
+	dat, err := os.ReadFile(loc)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading: %w", err)
+	}
+	if len(dat) == 0 {
+		return nil
+	}
+
+	dir := BackupDir(loc)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating backup dir: %w", err)
+	}
+
+	prefix, ext := backupPrefix(loc)
+	name := prefix + time.Now().UTC().Format(backupTimeFormat) + ext
+	if err := writeFileAtomic(filepath.Join(dir, name), dat, 0644); err != nil {
+		return fmt.Errorf("writing backup: %w", err)
+	}
+
+	bs, err := Backups(loc)
+	if err != nil {
+		return fmt.Errorf("listing backups: %w", err)
+	}
+	for i := maxBackups; i < len(bs); i++ {
+		if err := os.Remove(bs[i].Path); err != nil {
+			return fmt.Errorf("removing old backup: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file beside loc, syncs it to disk and then renames it
+// over loc so a crash mid write never leaves a partially written file behind.
+func writeFileAtomic(loc string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(loc)
+
+	f, err := os.CreateTemp(dir, "."+filepath.Base(loc)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("syncing temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing temp file: %w", err)
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return fmt.Errorf("setting permissions: %w", err)
+	}
+
+	if err := os.Rename(tmp, loc); err != nil {
+		return fmt.Errorf("replacing file: %w", err)
+	}
+
+	// sync the directory so the rename itself survives a crash. not all platforms support this.
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+
+	return nil
+}
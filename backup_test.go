@@ -0,0 +1,81 @@
+package witcharcana
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveBackups(t *testing.T) {
+	dir := t.TempDir()
+	loc := filepath.Join(dir, "clubs.json")
+
+	// the first save has nothing to back up
+	assert.NoError(t, Save(loc, map[string]*Club{"CNT": {Name: "CNT"}}))
+	bs, err := Backups(loc)
+	assert.NoError(t, err)
+	assert.Empty(t, bs)
+
+	assert.NoError(t, Save(loc, map[string]*Club{"SP": {Name: "SP"}}))
+	bs, err = Backups(loc)
+	assert.NoError(t, err)
+	if assert.Len(t, bs, 1) {
+		cs, err := loadData(bs[0].Path)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]*Club{"CNT": {Name: "CNT"}}, cs.clubs)
+	}
+
+	for i := 0; i < maxBackups+2; i++ {
+		assert.NoError(t, Save(loc, map[string]*Club{"MID": {Name: fmt.Sprint(i)}}))
+	}
+	bs, err = Backups(loc)
+	assert.NoError(t, err)
+	assert.Len(t, bs, maxBackups)
+	for i := 1; i < len(bs); i++ {
+		assert.True(t, bs[i-1].Time.After(bs[i].Time), "backups should be newest first")
+	}
+
+	// no temp files are left behind
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{".backups", "clubs.json"}, names)
+}
+
+func TestRestoreBackup(t *testing.T) {
+	loc := filepath.Join(t.TempDir(), "clubs.json")
+
+	assert.NoError(t, Save(loc, map[string]*Club{"CNT": {Name: "CNT"}}))
+	assert.NoError(t, Save(loc, map[string]*Club{"SP": {Name: "SP"}}))
+
+	bs, err := Backups(loc)
+	assert.NoError(t, err)
+	assert.Len(t, bs, 1)
+
+	t.Run("unknown backup", func(t *testing.T) {
+		assert.EqualError(t, RestoreBackup(loc, "nope.json"), `backup "nope.json": file does not exist`)
+	})
+
+	t.Run("restored", func(t *testing.T) {
+		assert.NoError(t, RestoreBackup(loc, bs[0].Name))
+
+		cs, err := loadData(loc)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]*Club{"CNT": {Name: "CNT"}}, cs.clubs)
+
+		// the replaced data is backed up as well
+		after, err := Backups(loc)
+		assert.NoError(t, err)
+		if assert.Len(t, after, 2) {
+			cs, err := loadData(after[0].Path)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]*Club{"SP": {Name: "SP"}}, cs.clubs)
+		}
+	})
+}
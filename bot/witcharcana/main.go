@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -14,48 +17,125 @@ import (
 )
 
 var (
-	BotToken     = flag.String("token", "", "Bot access token")
-	DataLocation = flag.String("data", "", "Location of data")
-	DBConn       = flag.String("dbconn", "", "database location")
-	DBUser       = flag.String("dbuser", "", "database user")
-	DBPass       = flag.String("dbpass", "", "database password")
-	DBName       = flag.String("dbname", "", "database name")
-	Debug        = flag.Bool("debug", false, "enable verbose logging")
+	ConfigLocation = flag.String("config", "", "config file to read settings from. defaults to witcharcana.yaml if found")
+	Backend        = flag.String("backend", "", "where club data is stored: file or mongo")
+	BotToken       = flag.String("token", "", "Bot access token. prefer WITCHARCANA_TOKEN to keep it out of process lists")
+	DataLocation   = flag.String("data", "", "Location of data")
+	TemplateDir    = flag.String("template-dir", "", "directory of templates overriding the built in ones")
+	DBConn         = flag.String("dbconn", "", "database location")
+	DBUser         = flag.String("dbuser", "", "database user")
+	DBPass         = flag.String("dbpass", "", "database password. prefer WITCHARCANA_DB_PASS to keep it out of process lists")
+	DBName         = flag.String("dbname", "", "database name")
+	Debug          = flag.Bool("debug", false, "enable verbose logging")
 )
 
+// configFlags maps the bot's flags onto the settings they override.
+var configFlags = map[string]string{
+	"backend":      "backend",
+	"token":        "token",
+	"data":         "data",
+	"template-dir": "template-dir",
+	"dbconn":       "db-conn",
+	"dbuser":       "db-user",
+	"dbpass":       "db-pass",
+	"dbname":       "db-name",
+	"debug":        "verbose",
+}
+
+// clubsMu guards the club data, whose collection is switched per guild, between message handlers,
+// scheduled sheet imports and the scheduler.
+var clubsMu sync.Mutex
+
+// _lockTimeout is how long a change waits for others, such as the cli, to finish with the data file.
+const _lockTimeout = 5 * time.Second
+
+// lockData locks the data file, when club data is kept in one, and reloads it so a change is made
+// to the latest saved data. The returned func releases the lock. Callers hold clubsMu.
+func lockData(cs *wa.Clubs) (unlock func(), err error) {
+	loc := cs.DataLocation()
+	if loc == "" {
+		return func() {}, nil
+	}
+
+	l, err := wa.LockFile(loc, _lockTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("locking data file: %w", err)
+	}
+	if err := cs.Reload(); err != nil {
+		l.Unlock()
+		return nil, err
+	}
+
+	return func() {
+		if err := l.Unlock(); err != nil {
+			log.Printf("unlocking data file: %v", err)
+		}
+	}, nil
+}
+
+// saveData saves a change made under lockData. When the change failed with err, or can't be saved,
+// it's discarded by reloading the data file so memory matches what was saved.
+func saveData(cs *wa.Clubs, err error) error {
+	if err == nil {
+		if err = cs.Save(); err != nil {
+			err = fmt.Errorf("saving data: %w", err)
+		}
+	}
+	if err != nil {
+		if rerr := cs.Reload(); rerr != nil {
+			log.Printf("discarding unsaved changes: %v", rerr)
+		}
+	}
+
+	return err
+}
+
 func main() {
 	flag.Parse()
 
-	s, err := discordgo.New("Bot " + *BotToken)
+	cfg, err := configure()
 	if err != nil {
-		log.Fatalf("creating new session: %v", err)
+		log.Fatalf("configuring: %v", err)
 	}
 
-	// cs, err := loadClubs(*DataLocation)
-	// if err != nil {
-	// 	log.Fatalf("could not load data: %v", err)
-	// }
+	s, err := discordgo.New("Bot " + cfg.Token)
+	if err != nil {
+		log.Fatalf("creating new session: %v", err)
+	}
 
 	ctx := context.Background()
-	dbcfg := &wa.DBConfig{
-		Loc:  *DBConn,
-		Name: *DBName,
-		User: *DBUser,
-		Pass: *DBPass,
-	}
 
-	db := wa.NewDB(ctx, dbcfg)
-	if err := db.Connect(); err != nil {
-		log.Fatalf("connecting to db: %v", err)
-	}
-	if err := db.Ping(); err != nil {
-		log.Fatalf("db unreachable: %v", err)
+	var cs *wa.Clubs
+	switch cfg.Backend {
+	case wa.BackendMongo:
+		db := wa.NewDB(&cfg.DB)
+		if err := db.Connect(ctx); err != nil {
+			log.Fatalf("connecting to db: %v", err)
+		}
+		defer db.Disconnect(ctx)
+		if err := db.Ping(ctx); err != nil {
+			log.Fatalf("db unreachable: %v", err)
+		}
+
+		cs = wa.NewClubs(db, cfg.Verbose)
+	case wa.BackendFile:
+		// every server shares the data file as collections only exist in the database.
+		cs = wa.NewClubs(nil, cfg.Verbose)
+		if err := cs.LoadData(cfg.Data); err != nil {
+			log.Fatalf("could not load data: %v", err)
+		}
 	}
 
-	cs := wa.NewClubs(db, *Debug)
+	si := newSheetImports(cs, _sheetTimeout)
+	t := wa.NewTemplates(cfg.TemplateDir)
+	sc := newScheduler(cs, t, realClock{}, func(channelID, msg string) {
+		if _, err := s.ChannelMessageSend(channelID, msg); err != nil {
+			log.Printf("could not send scheduled message: %v", err)
+		}
+	})
 
 	s.AddHandler(startUp)
-	s.AddHandler(messageHandler(cs))
+	s.AddHandler(messageHandler(cs, si, sc, t))
 
 	err = s.Open()
 	if err != nil {
@@ -63,36 +143,69 @@ func main() {
 	}
 	defer s.Close()
 
+	// schedules only run once the session can post their messages.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go sc.run(ctx)
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt)
 	<-stop
 	log.Println("Shutting down")
 }
 
-// func loadClubs(dataLoc string) (*wa.Clubs, error) {
-// 	cs := wa.NewClubs(true)
-// 	if err := cs.LoadData(dataLoc); err != nil {
-// 		return nil, fmt.Errorf("loading data: %w", err)
-// 	}
+// configure loads settings from the config file and environment, overridden by any flags given. The
+// bot stores data in mongo unless configured otherwise.
+func configure() (*wa.Config, error) {
+	def := wa.DefaultConfig()
+	def.Backend = wa.BackendMongo
 
-// 	return cs, nil
-// }
+	cfg, err := wa.LoadConfig(*ConfigLocation, def)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	var errs []error
+	flag.Visit(func(f *flag.Flag) {
+		if k, ok := configFlags[f.Name]; ok {
+			errs = append(errs, cfg.Set(k, f.Value.String()))
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("reading flags: %w", err)
+	}
+
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("bot token required. set it with %s", wa.EnvVar("token"))
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
 
 func startUp(s *discordgo.Session, r *discordgo.Ready) {
 	log.Println("Bot is up!")
 }
 
-func messageHandler(cs *wa.Clubs) func(s *discordgo.Session, m *discordgo.MessageCreate) {
+func messageHandler(cs *wa.Clubs, si *sheetImports, sc *scheduler, t *wa.Templates) func(s *discordgo.Session, m *discordgo.MessageCreate) {
 	return func(s *discordgo.Session, m *discordgo.MessageCreate) {
 		// filter bots or messages not intended for this one.
 		if m.Author.Bot || m.Content[:4] != "!wat" {
 			return
 		}
 
-		d, err := handleMessage(cs, m)
+		post := func(msg string) {
+			if _, err := s.ChannelMessageSend(m.ChannelID, msg); err != nil {
+				log.Printf("could not send message: %v", err)
+			}
+		}
+
+		d, err := handleMessage(context.Background(), cs, si, sc, t, m, post)
 		if err != nil {
-			msg := fmt.Sprintf("bad request: %v", err)
-			_, err := s.ChannelMessageSend(m.ChannelID, msg)
+			log.Printf("handling message: %v", err)
+			_, err := s.ChannelMessageSend(m.ChannelID, errorReply(err))
 			if err != nil {
 				log.Printf("could not send error message: %v", err)
 			}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	wa "github.com/mxygem/witch-arcana"
+)
+
+func testDataFile(t *testing.T) (string, *wa.Clubs) {
+	t.Helper()
+
+	loc := filepath.Join(t.TempDir(), "clubs.json")
+	require.NoError(t, wa.Save(loc, map[string]*wa.Club{"AZA": {Name: "AZA"}}))
+	cs := wa.NewClubs(nil, false)
+	require.NoError(t, cs.LoadData(loc))
+
+	return loc, cs
+}
+
+func TestHandleMessageSavedElsewhere(t *testing.T) {
+	loc, cs := testDataFile(t)
+	tmpls := wa.NewTemplates("")
+	sc := newScheduler(cs, tmpls, realClock{}, func(string, string) {})
+	send := func(content string) (any, error) {
+		m := &discordgo.MessageCreate{Message: &discordgo.Message{
+			GuildID: "g1",
+			Content: content,
+			Author:  &discordgo.User{Username: "mxygem"},
+		}}
+
+		return handleMessage(context.Background(), cs, nil, sc, tmpls, m, func(string) {})
+	}
+
+	// the cli saves a change after the bot loaded the data file
+	cli := wa.NewClubs(nil, false)
+	require.NoError(t, cli.LoadData(loc))
+	require.NoError(t, cli.CreateClub(context.Background(), &wa.Club{Name: "SP"}))
+	require.NoError(t, cli.Save())
+
+	_, err := send("!wat get club SP")
+	assert.NoError(t, err, "changes saved elsewhere are seen")
+
+	_, err = send("!wat add club CNT")
+	assert.NoError(t, err)
+
+	saved := wa.NewClubs(nil, false)
+	require.NoError(t, saved.LoadData(loc))
+	assert.Len(t, saved.All(), 3)
+}
+
+func TestSaveDataDiscardsFailedChanges(t *testing.T) {
+	_, cs := testDataFile(t)
+
+	unlock, err := lockData(cs)
+	require.NoError(t, err)
+	defer unlock()
+
+	require.NoError(t, cs.CreateClub(context.Background(), &wa.Club{Name: "SP"}))
+	err = saveData(cs, errors.New("boom"))
+
+	assert.EqualError(t, err, "boom")
+	assert.Len(t, cs.All(), 1, "the unsaved club is discarded")
+}
+
+func TestHandleMessageClubTagsAndNotes(t *testing.T) {
+	loc, cs := testDataFile(t)
+	tmpls := wa.NewTemplates("")
+	sc := newScheduler(cs, tmpls, realClock{}, func(string, string) {})
+	send := func(content string) (any, error) {
+		m := &discordgo.MessageCreate{Message: &discordgo.Message{
+			GuildID: "g1",
+			Content: content,
+			Author:  &discordgo.User{Username: "mxygem"},
+		}}
+
+		return handleMessage(context.Background(), cs, nil, sc, tmpls, m, func(string) {})
+	}
+
+	_, err := send("!wat add tag club AZA kvk,farm")
+	assert.NoError(t, err)
+	_, err = send("!wat remove tag club AZA farm")
+	assert.NoError(t, err)
+	_, err = send("!wat update notes club AZA recruiting for kvk")
+	assert.NoError(t, err)
+	_, err = send("!wat add tag club AZA")
+	assert.EqualError(t, err, "club name and tags required. example: `!wat add tag club <club> <tags...>`")
+
+	saved := wa.NewClubs(nil, false)
+	require.NoError(t, saved.LoadData(loc))
+	c, err := saved.Club(context.Background(), "AZA")
+	require.NoError(t, err)
+	assert.Equal(t, wa.Tags{"kvk"}, c.Tags)
+	assert.Equal(t, "recruiting for kvk", c.Notes)
+
+	reply, err := send("!wat get notes club AZA")
+	assert.NoError(t, err)
+	assert.Contains(t, reply, "Notes: recruiting for kvk")
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	wa "github.com/mxygem/witch-arcana"
@@ -12,9 +14,17 @@ import (
 
 const (
 	_invalidMsg = "invalid command sent. need action and resource. example: `add player`"
+	// _requestTimeout limits how long a club or player command can take.
+	_requestTimeout = 10 * time.Second
+	// _leaderboardTop is the number of players on a leaderboard unless asked for more or less.
+	_leaderboardTop = 20
+	// _inactiveDays is how long a player's stats must be unchanged to be reported unless asked otherwise.
+	_inactiveDays = 14
+	// _maxLeaderboardTop keeps leaderboards within discord's message length limit.
+	_maxLeaderboardTop = 30
 )
 
-func handleMessage(cs *wa.Clubs, m *discordgo.MessageCreate) (any, error) {
+func handleMessage(ctx context.Context, cs *wa.Clubs, si *sheetImports, sc *scheduler, t *wa.Templates, m *discordgo.MessageCreate, post func(string)) (reply any, err error) {
 	log.Printf("guild: %v channel: %v user: %v\n", m.GuildID, m.ChannelID, m.Author.Username)
 
 	msg := strings.TrimSpace(m.Content[4:])
@@ -29,9 +39,42 @@ func handleMessage(cs *wa.Clubs, m *discordgo.MessageCreate) (any, error) {
 	action := d[0]
 	resource := d[1]
 
-	resources := []string{"club", "player"}
+	resources := []string{"club", "player", "sheet", "stats", "leaderboard", "prospect", "inactive", "tag", "notes", "role", "schedule"}
 	actions := []string{"get", "add", "update", "remove"}
-	playerActions := append(actions, "move")
+	playerActions := append(actions, "move", "promote", "demote")
+	prospectActions := []string{"get", "add", "remove", "promote"}
+	tagActions := []string{"get", "add", "remove"}
+	notesActions := []string{"get", "update"}
+	scheduleActions := []string{"get", "add", "remove"}
+
+	// sheet imports manage access to the club data themselves as they may run long.
+	if resource == resources[2] {
+		return handleSheetMessage(ctx, si, m, action, d[2:], post)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _requestTimeout)
+	defer cancel()
+
+	clubsMu.Lock()
+	defer clubsMu.Unlock()
+
+	// writes are made to the latest saved data and saved once handled, before the data file is
+	// unlocked. reads see the latest saved data too.
+	if writes(action) {
+		unlock, lerr := lockData(cs)
+		if lerr != nil {
+			return nil, lerr
+		}
+		defer unlock()
+
+		defer func() {
+			if err = saveData(cs, err); err != nil {
+				reply = nil
+			}
+		}()
+	} else if rerr := cs.Reload(); rerr != nil {
+		return nil, rerr
+	}
 
 	// once command is valid, set collection as guildID
 	cs.SetCollection(m.GuildID)
@@ -43,17 +86,12 @@ func handleMessage(cs *wa.Clubs, m *discordgo.MessageCreate) (any, error) {
 		// get club
 		case actions[0]:
 			log.Println("get club")
-			c, err := cs.Club(d[2])
+			c, err := cs.Club(ctx, d[2])
 			if err != nil {
 				return nil, fmt.Errorf("getting club: %w", err)
 			}
 
-			o, err := wa.PrettyJSON(c)
-			if err != nil {
-				return nil, fmt.Errorf("formatting data: %w", err)
-			}
-
-			return string(o), nil
+			return render(t, c)
 		// add club
 		case actions[1]:
 			log.Println("add club")
@@ -74,19 +112,39 @@ func handleMessage(cs *wa.Clubs, m *discordgo.MessageCreate) (any, error) {
 			}
 
 			c := wa.NewClub(d[2], x, y)
-			if err := cs.CreateClub(c); err != nil {
+			if err := cs.CreateClub(ctx, c); err != nil {
 				return nil, fmt.Errorf("creating club: %w", err)
 			}
 
-			o, err := wa.PrettyJSON(c)
-			if err != nil {
-				return nil, fmt.Errorf("formatting data: %w", err)
-			}
-
-			return string(o), nil
+			return render(t, c)
 		// update club
 		case actions[2]:
 			log.Println("update club")
+			// !wat update club <name> capacity <n> or recruitment <open|invite|closed>
+			if len(d) == 5 && (d[3] == "capacity" || d[3] == "recruitment") {
+				c := &wa.Club{Name: d[2]}
+				if d[3] == "capacity" {
+					n, err := strconv.Atoi(d[4])
+					if err != nil {
+						return nil, fmt.Errorf("argument for capacity: %q is not a valid number", d[4])
+					}
+					c.Capacity = n
+				} else {
+					r, err := wa.ParseRecruitment(d[4])
+					if err != nil {
+						return nil, err
+					}
+					c.Recruitment = r
+				}
+
+				nc, err := cs.UpdateClub(ctx, c)
+				if err != nil {
+					return nil, fmt.Errorf("updating club: %w", err)
+				}
+
+				return render(t, nc)
+			}
+
 			var x, y int
 			if len(d) == 5 {
 				xs, err := strconv.Atoi(d[3])
@@ -103,22 +161,17 @@ func handleMessage(cs *wa.Clubs, m *discordgo.MessageCreate) (any, error) {
 			}
 			c := wa.NewClub(d[2], x, y)
 
-			nc, err := cs.UpdateClub(c)
-			if err != nil {
-				return nil, fmt.Errorf("updating club: %v", err)
-			}
-
-			o, err := wa.PrettyJSON(nc)
+			nc, err := cs.UpdateClub(ctx, c)
 			if err != nil {
-				return nil, fmt.Errorf("formatting data: %w", err)
+				return nil, fmt.Errorf("updating club: %w", err)
 			}
 
-			return string(o), nil
+			return render(t, nc)
 		// remove club
 		case actions[3]:
 			fmt.Println("remove club")
-			if err := cs.RemoveClub(d[2]); err != nil {
-				return nil, fmt.Errorf("removing club: %v", err)
+			if err := cs.RemoveClub(ctx, d[2]); err != nil {
+				return nil, fmt.Errorf("removing club: %w", err)
 			}
 		default:
 			return nil, fmt.Errorf("unknown club action %q found. options: %v", action, actions)
@@ -155,89 +208,468 @@ func handleMessage(cs *wa.Clubs, m *discordgo.MessageCreate) (any, error) {
 			y = ys
 		}
 
+		// might follows the location, which may be given as 0 0 to leave it unset.
+		var might int64
+		if len(d) >= 8 {
+			m, err := wa.ParseMight(d[7])
+			if err != nil {
+				return nil, fmt.Errorf("argument for might: %w", err)
+			}
+			might = m
+		}
+
 		fmt.Printf("player name: %q\n", d[2])
 		p := wa.NewPlayer(d[2], clubName, level, x, y)
+		p.Might = might
 
 		switch action {
 		// get player
 		case playerActions[0]:
 			log.Println("get player")
-			gp, err := cs.Player(p.Name)
+			gp, err := cs.Player(ctx, p.Name)
 			if err != nil {
-				return nil, fmt.Errorf("getting player: %v", err)
+				return nil, fmt.Errorf("getting player: %w", err)
 			}
 
-			o, err := wa.PrettyJSON(gp)
-			if err != nil {
-				return nil, fmt.Errorf("formatting data: %w", err)
-			}
-
-			return string(o), nil
+			return render(t, gp)
 		// add player
 		case playerActions[1]:
 			log.Println("add player")
-			np, err := cs.CreatePlayer(p.Club, p)
+			np, err := cs.CreatePlayer(ctx, p.Club, p)
 			if err != nil {
-				return nil, fmt.Errorf("creating player: %v", err)
+				return nil, fmt.Errorf("creating player: %w", err)
 			}
 
-			o, err := wa.PrettyJSON(np)
-			if err != nil {
-				return nil, fmt.Errorf("formatting data: %w", err)
-			}
-
-			return string(o), nil
+			return renderWithCapacity(ctx, cs, t, np, p.Club)
 		// update player
 		case playerActions[2]:
 			log.Println("update player")
 
-			up, err := cs.UpdatePlayer(p)
-			if err != nil {
-				log.Fatalf("updating player: %v", err)
-			}
-
-			o, err := wa.PrettyJSON(up)
+			up, err := cs.UpdatePlayer(ctx, p)
 			if err != nil {
-				return nil, fmt.Errorf("formatting data: %w", err)
+				return nil, fmt.Errorf("updating player: %w", err)
 			}
 
-			return string(o), nil
+			return render(t, up)
 		// remove player
 		case playerActions[3]:
 			log.Println("remove player")
 
-			if err := cs.RemovePlayer(d[3]); err != nil {
-				log.Fatalf("removing player: %v", err)
+			if err := cs.RemovePlayer(ctx, d[3]); err != nil {
+				return nil, fmt.Errorf("removing player: %w", err)
 			}
 		case playerActions[4]:
 			log.Println("move player")
-			mp, err := cs.MovePlayer(p.Name, p.Club)
+			mp, err := cs.MovePlayer(ctx, p.Name, p.Club)
 			if err != nil {
-				log.Fatalf("moving player: %v", err)
+				return nil, fmt.Errorf("moving player: %w", err)
 			}
 
-			o, err := wa.PrettyJSON(mp)
+			return renderWithCapacity(ctx, cs, t, mp, p.Club)
+		// promote player: !wat promote player <name>
+		case playerActions[5]:
+			chs, err := cs.PromotePlayer(ctx, p.Name)
 			if err != nil {
-				return nil, fmt.Errorf("formatting data: %w", err)
+				return nil, fmt.Errorf("promoting player: %w", err)
 			}
 
-			return string(o), nil
+			return roleChanges(chs), nil
+		// demote player: !wat demote player <name>
+		case playerActions[6]:
+			chs, err := cs.DemotePlayer(ctx, p.Name)
+			if err != nil {
+				return nil, fmt.Errorf("demoting player: %w", err)
+			}
+
+			return roleChanges(chs), nil
 		default:
 			return nil, fmt.Errorf("unknown player action %q found. options: %v", action, playerActions)
 		}
+	// stats: !wat get stats [club]
+	case resources[3]:
+		if action != actions[0] {
+			return nil, fmt.Errorf("unknown stats action %q found. options: %v", action, actions[:1])
+		}
+
+		var clubName string
+		if len(d) >= 3 {
+			clubName = d[2]
+		}
+
+		st, err := cs.Stats(ctx, clubName)
+		if err != nil {
+			return nil, fmt.Errorf("getting stats: %w", err)
+		}
+
+		return render(t, st)
+	// leaderboard: !wat get leaderboard [might|level] [top] [club]
+	case resources[4]:
+		if action != actions[0] {
+			return nil, fmt.Errorf("unknown leaderboard action %q found. options: %v", action, actions[:1])
+		}
+
+		by := wa.RankByMight
+		if len(d) >= 3 {
+			b, err := wa.ParseRankBy(d[2])
+			if err != nil {
+				return nil, err
+			}
+			by = b
+		}
+
+		top := _leaderboardTop
+		if len(d) >= 4 {
+			n, err := strconv.Atoi(d[3])
+			if err != nil {
+				return nil, fmt.Errorf("argument for top: %q is not a valid number", d[3])
+			}
+			if n < 1 || n > _maxLeaderboardTop {
+				return nil, fmt.Errorf("argument for top: must be between 1 and %d", _maxLeaderboardTop)
+			}
+			top = n
+		}
+
+		var clubName string
+		if len(d) >= 5 {
+			clubName = d[4]
+		}
+
+		lb, err := cs.Leaderboard(ctx, by, top, clubName)
+		if err != nil {
+			return nil, fmt.Errorf("getting leaderboard: %w", err)
+		}
+
+		return render(t, lb)
+	// prospect: !wat <action> prospect <name> <club> or !wat get prospect <club>
+	case resources[5]:
+		if action == prospectActions[0] {
+			if len(d) < 3 {
+				return nil, fmt.Errorf("club name required. example: `!wat get prospect <club>`")
+			}
+
+			prs, err := cs.Prospects(ctx, d[2])
+			if err != nil {
+				return nil, fmt.Errorf("getting prospects: %w", err)
+			}
+
+			return render(t, prs)
+		}
+
+		if len(d) < 4 {
+			return nil, fmt.Errorf("prospect and club names required. example: `!wat %s prospect <name> <club>`", action)
+		}
+		name, clubName := d[2], d[3]
+
+		switch action {
+		// add prospect: !wat add prospect <name> <club> [level] [might] [contact notes]
+		case prospectActions[1]:
+			pr := &wa.Prospect{Name: name}
+			if len(d) >= 5 {
+				l, err := strconv.Atoi(d[4])
+				if err != nil {
+					return nil, fmt.Errorf("argument for level: %q is not a valid number", d[4])
+				}
+				pr.Level = l
+			}
+			if len(d) >= 6 {
+				m, err := wa.ParseMight(d[5])
+				if err != nil {
+					return nil, fmt.Errorf("argument for might: %w", err)
+				}
+				pr.Might = m
+			}
+			if len(d) >= 7 {
+				pr.Contact = strings.Join(d[6:], " ")
+			}
+
+			if err := cs.AddProspect(ctx, clubName, pr); err != nil {
+				return nil, fmt.Errorf("adding prospect: %w", err)
+			}
+
+			return render(t, wa.Prospects{pr})
+		case prospectActions[2]:
+			if err := cs.RemoveProspect(ctx, clubName, name); err != nil {
+				return nil, fmt.Errorf("removing prospect: %w", err)
+			}
+		case prospectActions[3]:
+			p, err := cs.PromoteProspect(ctx, clubName, name)
+			if err != nil {
+				return nil, fmt.Errorf("promoting prospect: %w", err)
+			}
+
+			return renderWithCapacity(ctx, cs, t, p, clubName)
+		default:
+			return nil, fmt.Errorf("unknown prospect action %q found. options: %v", action, prospectActions)
+		}
+	// inactive: !wat get inactive [days] [snapshots] [club] reports players that look inactive and
+	// !wat update inactive with the same arguments marks them as inactive.
+	case resources[6]:
+		if action != actions[0] && action != actions[2] {
+			return nil, fmt.Errorf("unknown inactive action %q found. options: %v", action, []string{actions[0], actions[2]})
+		}
+
+		opts := &wa.InactivityOptions{Days: _inactiveDays}
+		if len(d) >= 3 {
+			n, err := strconv.Atoi(d[2])
+			if err != nil {
+				return nil, fmt.Errorf("argument for days: %q is not a valid number", d[2])
+			}
+			opts.Days = n
+		}
+		if len(d) >= 4 {
+			n, err := strconv.Atoi(d[3])
+			if err != nil {
+				return nil, fmt.Errorf("argument for snapshots: %q is not a valid number", d[3])
+			}
+			opts.Snapshots = n
+		}
+		if len(d) >= 5 {
+			opts.Club = d[4]
+		}
+
+		report, err := cs.InactivityReport(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("reporting inactive players: %w", err)
+		}
+
+		r, err := render(t, report)
+		if err != nil || action == actions[0] {
+			return r, err
+		}
+
+		names := make([]string, len(report))
+		for i, ip := range report {
+			names[i] = ip.Name
+		}
+		chs, err := cs.MarkInactive(ctx, names...)
+		if err != nil {
+			return nil, fmt.Errorf("marking players inactive: %w", err)
+		}
+
+		return fmt.Sprintf("%s\nmarked %d players inactive", r, len(chs.Updated)), nil
+	// tag: !wat get tag <tags> [club] lists the players with every tag and !wat add|remove tag
+	// <player> <tags...> changes a player's tags. !wat add|remove tag club <club> <tags...> changes a
+	// club's tags.
+	case resources[7]:
+		if action == tagActions[0] {
+			if len(d) < 3 {
+				return nil, fmt.Errorf("tags required. example: `!wat get tag farm,kvk-captain [club]`")
+			}
+
+			ts, err := wa.ParseTags(d[2])
+			if err != nil {
+				return nil, err
+			}
+
+			var clubName string
+			if len(d) >= 4 {
+				clubName = d[3]
+			}
+
+			ps, err := cs.TaggedPlayers(ctx, clubName, ts...)
+			if err != nil {
+				return nil, fmt.Errorf("getting tagged players: %w", err)
+			}
+
+			return render(t, ps)
+		}
+
+		if len(d) >= 3 && d[2] == resources[0] {
+			if len(d) < 5 {
+				return nil, fmt.Errorf("club name and tags required. example: `!wat %s tag club <club> <tags...>`", action)
+			}
+
+			ts, err := wa.ParseTags(strings.Join(d[4:], " "))
+			if err != nil {
+				return nil, err
+			}
+
+			var c *wa.Club
+			switch action {
+			case tagActions[1]:
+				c, err = cs.TagClub(ctx, d[3], ts...)
+			case tagActions[2]:
+				c, err = cs.UntagClub(ctx, d[3], ts...)
+			default:
+				return nil, fmt.Errorf("unknown tag action %q found. options: %v", action, tagActions)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("tagging club: %w", err)
+			}
+
+			return render(t, c)
+		}
+
+		if len(d) < 4 {
+			return nil, fmt.Errorf("player name and tags required. example: `!wat %s tag <player> <tags...>`", action)
+		}
+
+		ts, err := wa.ParseTags(strings.Join(d[3:], " "))
+		if err != nil {
+			return nil, err
+		}
+
+		var p *wa.Player
+		switch action {
+		case tagActions[1]:
+			p, err = cs.TagPlayer(ctx, d[2], ts...)
+		case tagActions[2]:
+			p, err = cs.UntagPlayer(ctx, d[2], ts...)
+		default:
+			return nil, fmt.Errorf("unknown tag action %q found. options: %v", action, tagActions)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tagging player: %w", err)
+		}
+
+		return render(t, p)
+	// notes: !wat get notes <player> or !wat update notes <player> [notes...], clearing them when
+	// none are given. !wat get|update notes club <club> [notes...] does the same for a club.
+	case resources[8]:
+		if len(d) < 3 {
+			return nil, fmt.Errorf("player name required. example: `!wat %s notes <player>`", action)
+		}
+
+		if d[2] == resources[0] {
+			if len(d) < 4 {
+				return nil, fmt.Errorf("club name required. example: `!wat %s notes club <club>`", action)
+			}
+
+			switch action {
+			case notesActions[0]:
+				c, err := cs.Club(ctx, d[3])
+				if err != nil {
+					return nil, fmt.Errorf("getting club: %w", err)
+				}
+
+				return render(t, c)
+			case notesActions[1]:
+				c, err := cs.SetClubNotes(ctx, d[3], strings.Join(d[4:], " "))
+				if err != nil {
+					return nil, fmt.Errorf("updating notes: %w", err)
+				}
+
+				return render(t, c)
+			default:
+				return nil, fmt.Errorf("unknown notes action %q found. options: %v", action, notesActions)
+			}
+		}
+
+		switch action {
+		case notesActions[0]:
+			p, err := cs.Player(ctx, d[2])
+			if err != nil {
+				return nil, fmt.Errorf("getting player: %w", err)
+			}
+
+			return render(t, p)
+		case notesActions[1]:
+			p, err := cs.SetPlayerNotes(ctx, d[2], strings.Join(d[3:], " "))
+			if err != nil {
+				return nil, fmt.Errorf("updating notes: %w", err)
+			}
+
+			return render(t, p)
+		default:
+			return nil, fmt.Errorf("unknown notes action %q found. options: %v", action, notesActions)
+		}
+	// role: !wat update role <player> <leader|officer|member|custom role>
+	case resources[9]:
+		if action != actions[2] {
+			return nil, fmt.Errorf("unknown role action %q found. options: %v", action, actions[2:3])
+		}
+		if len(d) < 4 {
+			return nil, fmt.Errorf("player name and role required. example: `!wat update role <player> officer`")
+		}
+
+		r, err := wa.ParseRole(d[3])
+		if err != nil {
+			return nil, err
+		}
+
+		chs, err := cs.SetRole(ctx, d[2], r)
+		if err != nil {
+			return nil, fmt.Errorf("updating role: %w", err)
+		}
+
+		return roleChanges(chs), nil
+	// schedule: !wat get schedule, !wat remove schedule <id> or !wat add schedule <kind> <every>
+	// <start> followed by the club of roster summaries or the message of reminders and stats pings.
+	case resources[10]:
+		switch action {
+		case scheduleActions[0]:
+			ss, err := cs.Schedules(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			return render(t, ss.Guild(m.GuildID))
+		case scheduleActions[1]:
+			if len(d) < 5 {
+				return nil, fmt.Errorf("schedule kind, interval and start required. example: `!wat add schedule reminder weekly 2026-10-20T18:00 club war tonight!`")
+			}
+
+			kind, err := wa.ParseScheduleKind(d[2])
+			if err != nil {
+				return nil, err
+			}
+			every, err := wa.ParseEvery(d[3])
+			if err != nil {
+				return nil, err
+			}
+			start, err := time.ParseInLocation(wa.ScheduleStartLayout, d[4], time.UTC)
+			if err != nil {
+				return nil, fmt.Errorf("argument for start: %q is not a valid time. use YYYY-MM-DDTHH:MM in UTC", d[4])
+			}
+
+			s := &wa.Schedule{Guild: m.GuildID, Channel: m.ChannelID, Kind: kind, Start: start, Every: every}
+			if kind == wa.ScheduleRoster {
+				if len(d) >= 6 {
+					s.Club = d[5]
+				}
+			} else {
+				s.Message = strings.Join(d[5:], " ")
+			}
+
+			if err := cs.AddSchedule(ctx, s); err != nil {
+				return nil, fmt.Errorf("adding schedule: %w", err)
+			}
+			sc.reload()
+
+			return render(t, wa.Schedules{s})
+		case scheduleActions[2]:
+			if len(d) < 3 {
+				return nil, fmt.Errorf("schedule id required. example: `!wat remove schedule 1`")
+			}
+
+			id, err := strconv.Atoi(d[2])
+			if err != nil {
+				return nil, fmt.Errorf("argument for id: %q is not a valid number", d[2])
+			}
+
+			if err := cs.RemoveSchedule(ctx, m.GuildID, id); err != nil {
+				return nil, err
+			}
+			sc.reload()
+
+			return fmt.Sprintf("schedule %d removed", id), nil
+		default:
+			return nil, fmt.Errorf("unknown schedule action %q found. options: %v", action, scheduleActions)
+		}
 	default:
 		return nil, fmt.Errorf("unknown resource %q found. options: %v", resource, resources)
 	}
 
-	for _, a := range []string{"add", "update", "remove", "move"} {
-		if action != a {
-			continue
-		}
+	return nil, nil
+}
 
-		if err := wa.Save(cs.DataLocation(), cs.All()); err != nil {
-			return nil, fmt.Errorf("saving data: %w", err)
+// writes returns true if the action changes club data.
+func writes(action string) bool {
+	for _, a := range []string{"add", "update", "remove", "move", "promote", "demote"} {
+		if action == a {
+			return true
 		}
 	}
 
-	return nil, nil
+	return false
 }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	wa "github.com/mxygem/witch-arcana"
+)
+
+// render renders d with its default template as a code block so its layout survives in discord.
+func render(t *wa.Templates, d any) (string, error) {
+	name, err := wa.TemplateFor(d)
+	if err != nil {
+		return "", fmt.Errorf("rendering reply: %w", err)
+	}
+
+	return execute(t, name, d)
+}
+
+// execute renders d with the named template as a code block.
+func execute(t *wa.Templates, name string, d any) (string, error) {
+	var b strings.Builder
+	b.WriteString("```\n")
+	if err := t.Execute(&b, name, d); err != nil {
+		return "", fmt.Errorf("rendering reply: %w", err)
+	}
+	b.WriteString("```")
+
+	return b.String(), nil
+}
+
+// renderWithCapacity renders d like render, followed by a warning when the named club has more
+// players than its capacity.
+func renderWithCapacity(ctx context.Context, cs *wa.Clubs, t *wa.Templates, d any, clubName string) (string, error) {
+	r, err := render(t, d)
+	if err != nil {
+		return "", err
+	}
+
+	c, err := cs.Club(ctx, clubName)
+	if err != nil {
+		return r, nil
+	}
+	if w := c.CapacityWarning(); w != "" {
+		r += "\nwarning: " + w
+	}
+
+	return r, nil
+}
+
+// roleChanges describes the role changes made, including any leadership handed over.
+func roleChanges(chs *wa.ChangeSet) string {
+	if len(chs.Updated) == 0 {
+		return "no roles changed"
+	}
+
+	ls := make([]string, len(chs.Updated))
+	for i, pc := range chs.Updated {
+		f := pc.Fields[0]
+		ls[i] = fmt.Sprintf("%s (%s): %s -> %s", pc.Name, pc.Club, f.Old, f.New)
+	}
+
+	return strings.Join(ls, "\n")
+}
+
+// errorReply returns a friendly reply for errors users can fix themselves, falling back to reporting
+// the error as a bad request.
+func errorReply(err error) string {
+	var ve *wa.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		fs := make([]string, len(ve.Fields))
+		for i, f := range ve.Fields {
+			fs[i] = "- " + f.Error()
+		}
+		return fmt.Sprintf("that %s isn't quite right:\n%s", ve.Kind, strings.Join(fs, "\n"))
+	case errors.Is(err, wa.ErrClubNotFound):
+		return "I couldn't find that club. check the spelling or add it first with `!wat add club <name>`"
+	case errors.Is(err, wa.ErrPlayerNotFound):
+		return "I couldn't find that player. check the spelling or add them with `!wat add player <name> <club>`"
+	case errors.Is(err, wa.ErrProspectNotFound):
+		return "I couldn't find that prospect. see who's being recruited with `!wat get prospect <club>`"
+	case errors.Is(err, wa.ErrScheduleNotFound):
+		return "I couldn't find that schedule. see this server's schedules with `!wat get schedule`"
+	case errors.Is(err, wa.ErrAlreadyExists):
+		return "that already exists. use `update` instead of `add` to change it"
+	case errors.Is(err, wa.ErrDataChanged), errors.Is(err, wa.ErrLocked):
+		return "the data was being changed by someone else at the same time. please try again"
+	default:
+		return fmt.Sprintf("bad request: %v", err)
+	}
+}
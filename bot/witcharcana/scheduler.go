@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	wa "github.com/mxygem/witch-arcana"
+)
+
+const (
+	// _statsPing is posted by stats pings without a message of their own.
+	_statsPing = "time to update your stats! use `!wat update player <name> <club> <level> <x> <y> <might>`"
+	// _scheduleRetry is how long the scheduler waits before trying again when schedules can't be read.
+	_scheduleRetry = time.Minute
+)
+
+// clock tells the scheduler the time and lets it wait for time to pass, so tests can control both.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the system clock.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// scheduler posts the messages of every guild's schedules to their channels as they come due.
+type scheduler struct {
+	cs    *wa.Clubs
+	t     *wa.Templates
+	clock clock
+	post  func(channelID, msg string)
+
+	changed chan struct{}
+}
+
+func newScheduler(cs *wa.Clubs, t *wa.Templates, c clock, post func(channelID, msg string)) *scheduler {
+	return &scheduler{
+		cs:      cs,
+		t:       t,
+		clock:   c,
+		post:    post,
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// reload wakes the scheduler to pick up schedules that were added or removed.
+func (sc *scheduler) reload() {
+	select {
+	case sc.changed <- struct{}{}:
+	default:
+	}
+}
+
+// run posts schedules as they come due until ctx is done. Runs missed while the bot was down are
+// skipped and schedules that fell behind run once to catch up.
+func (sc *scheduler) run(ctx context.Context) {
+	since := sc.clock.Now()
+
+	for {
+		ss, err := sc.schedules(ctx)
+		if err != nil {
+			log.Printf("loading schedules: %v", err)
+		}
+
+		now := sc.clock.Now()
+		next := time.Time{}
+		for _, s := range ss {
+			if s.Due(since, now) {
+				sc.fire(ctx, s)
+			}
+
+			if n := s.Next(now); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+				next = n
+			}
+		}
+		since = now
+
+		var wait <-chan time.Time
+		switch {
+		case err != nil:
+			wait = sc.clock.After(_scheduleRetry)
+		case !next.IsZero():
+			wait = sc.clock.After(next.Sub(now))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-sc.changed:
+		case <-wait:
+		}
+	}
+}
+
+// schedules returns a copy of every guild's schedules, reading the latest saved data first.
+func (sc *scheduler) schedules(ctx context.Context) (wa.Schedules, error) {
+	clubsMu.Lock()
+	defer clubsMu.Unlock()
+
+	if err := sc.cs.Reload(); err != nil {
+		return nil, err
+	}
+
+	ss, err := sc.cs.Schedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(wa.Schedules{}, ss...), nil
+}
+
+// fire posts the schedule's message to its channel, or why it couldn't be made.
+func (sc *scheduler) fire(ctx context.Context, s *wa.Schedule) {
+	ctx, cancel := context.WithTimeout(ctx, _requestTimeout)
+	defer cancel()
+
+	msg, err := sc.message(ctx, s)
+	if err != nil {
+		log.Printf("running schedule %d for guild %v: %v", s.ID, s.Guild, err)
+		msg = fmt.Sprintf("scheduled %s %d failed: %v", s.Kind, s.ID, err)
+	}
+
+	sc.post(s.Channel, msg)
+}
+
+// message returns what the schedule posts when it runs.
+func (sc *scheduler) message(ctx context.Context, s *wa.Schedule) (string, error) {
+	switch s.Kind {
+	case wa.ScheduleReminder:
+		return s.Message, nil
+	case wa.ScheduleStatsPing:
+		if s.Message != "" {
+			return s.Message, nil
+		}
+
+		return _statsPing, nil
+	case wa.ScheduleRoster:
+		clubsMu.Lock()
+		defer clubsMu.Unlock()
+
+		sc.cs.SetCollection(s.Guild)
+
+		var d any
+		if s.Club != "" {
+			c, err := sc.cs.Club(ctx, s.Club)
+			if err != nil {
+				return "", fmt.Errorf("getting club: %w", err)
+			}
+			d = c
+		} else {
+			clubs, err := sc.cs.List(ctx)
+			if err != nil {
+				return "", err
+			}
+			d = clubs
+		}
+
+		name := s.Template
+		if name == "" {
+			name = "roster"
+		}
+
+		return execute(sc.t, name, d)
+	default:
+		return "", fmt.Errorf("unknown schedule kind %q", s.Kind)
+	}
+}
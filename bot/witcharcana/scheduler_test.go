@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	wa "github.com/mxygem/witch-arcana"
+)
+
+// fakeClock only moves when advanced, signalling waiting each time the scheduler starts waiting.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []fakeTimer
+	waiting chan struct{}
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, waiting: make(chan struct{}, 10)}
+}
+
+func (fc *fakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return fc.now
+}
+
+func (fc *fakeClock) After(d time.Duration) <-chan time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	c := make(chan time.Time, 1)
+	fc.timers = append(fc.timers, fakeTimer{at: fc.now.Add(d), c: c})
+	fc.waiting <- struct{}{}
+
+	return c
+}
+
+// advance moves the clock forward, firing the timers that are due.
+func (fc *fakeClock) advance(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.now = fc.now.Add(d)
+	var pending []fakeTimer
+	for _, t := range fc.timers {
+		if t.at.After(fc.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- fc.now
+	}
+	fc.timers = pending
+}
+
+type testPost struct {
+	channel, msg string
+}
+
+func testScheduler(t *testing.T, now time.Time, ss ...*wa.Schedule) (*scheduler, *fakeClock, chan testPost) {
+	t.Helper()
+
+	loc := filepath.Join(t.TempDir(), "clubs.json")
+	require.NoError(t, wa.Save(loc, map[string]*wa.Club{
+		"AZA": {Name: "AZA", Players: wa.Players{{Name: "Fayeee", Level: 18, Might: 70000000}}},
+	}))
+	cs := wa.NewClubs(nil, false)
+	require.NoError(t, cs.LoadData(loc))
+	for _, s := range ss {
+		require.NoError(t, cs.AddSchedule(context.Background(), s))
+	}
+	require.NoError(t, cs.Save())
+
+	fc := newFakeClock(now)
+	posts := make(chan testPost, 10)
+	sc := newScheduler(cs, wa.NewTemplates(""), fc, func(channel, msg string) { posts <- testPost{channel, msg} })
+
+	return sc, fc, posts
+}
+
+func noPost(t *testing.T, posts chan testPost) {
+	t.Helper()
+
+	select {
+	case p := <-posts:
+		t.Fatalf("unexpected post: %+v", p)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestSchedulerPostsWhenDue(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	sc, fc, posts := testScheduler(t, now,
+		&wa.Schedule{Guild: "g1", Channel: "c1", Kind: wa.ScheduleReminder, Start: now.Add(time.Hour), Every: 120, Message: "club war in 1 hour!"},
+		&wa.Schedule{Guild: "g1", Channel: "c2", Kind: wa.ScheduleRoster, Start: now.Add(90 * time.Minute), Every: 7 * 24 * 60, Club: "AZA"},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sc.run(ctx)
+
+	<-fc.waiting
+	fc.advance(30 * time.Minute)
+	noPost(t, posts)
+
+	fc.advance(30 * time.Minute)
+	assert.Equal(t, testPost{"c1", "club war in 1 hour!"}, <-posts)
+
+	<-fc.waiting
+	fc.advance(30 * time.Minute)
+	assert.Equal(t, testPost{"c2", "```\nAZA - 1 players\n  Fayeee                18    70m\n\n```"}, <-posts)
+
+	// the reminder runs again every 2 hours after its start
+	<-fc.waiting
+	fc.advance(time.Hour)
+	noPost(t, posts)
+	fc.advance(30 * time.Minute)
+	assert.Equal(t, "club war in 1 hour!", (<-posts).msg)
+}
+
+func TestSchedulerReload(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	sc, fc, posts := testScheduler(t, now)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sc.run(ctx)
+
+	// without schedules the scheduler waits to be told about new ones
+	time.Sleep(20 * time.Millisecond)
+	clubsMu.Lock()
+	err := sc.cs.AddSchedule(ctx, &wa.Schedule{Guild: "g1", Channel: "c1", Kind: wa.ScheduleStatsPing, Start: now.Add(time.Hour), Every: 24 * 60})
+	if err == nil {
+		err = sc.cs.Save()
+	}
+	clubsMu.Unlock()
+	require.NoError(t, err)
+	sc.reload()
+
+	<-fc.waiting
+	fc.advance(time.Hour)
+	assert.Equal(t, testPost{"c1", _statsPing}, <-posts)
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	wa "github.com/mxygem/witch-arcana"
+)
+
+// errSheetsFileOnly is returned by sheet commands when club data is kept in mongo, as bulk imports
+// only work against a data file.
+var errSheetsFileOnly = errors.New("sheet imports are only supported with the file backend")
+
+const (
+	_sheetTimeout      = 30 * time.Second
+	_minSheetInterval  = 5 * time.Minute
+	_maxRejectedListed = 5
+)
+
+// sheetImports imports player csvs from spreadsheet urls on behalf of guilds, either once or on a
+// schedule. Each guild has its own fetcher so a sheet one guild imported is still imported by
+// another.
+type sheetImports struct {
+	cs      *wa.Clubs
+	timeout time.Duration
+
+	mu        sync.Mutex
+	fetchers  map[string]*wa.CSVFetcher
+	schedules map[string]chan struct{}
+}
+
+func newSheetImports(cs *wa.Clubs, timeout time.Duration) *sheetImports {
+	return &sheetImports{
+		cs:        cs,
+		timeout:   timeout,
+		fetchers:  map[string]*wa.CSVFetcher{},
+		schedules: map[string]chan struct{}{},
+	}
+}
+
+// fetcher returns the guild's fetcher, creating it on first use.
+func (si *sheetImports) fetcher(guildID string) *wa.CSVFetcher {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	f, ok := si.fetchers[guildID]
+	if !ok {
+		f = wa.NewCSVFetcher(si.timeout)
+		si.fetchers[guildID] = f
+	}
+
+	return f
+}
+
+// run imports the sheet at url into the guild's data and returns a summary of the changes made. No
+// changes are made and changed is false when the sheet hasn't been modified since the last import.
+func (si *sheetImports) run(ctx context.Context, guildID, url string) (summary string, changed bool, err error) {
+	if si.cs.DataLocation() == "" {
+		return "", false, errSheetsFileOnly
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _sheetTimeout)
+	defer cancel()
+
+	res, err := si.fetcher(guildID).Fetch(ctx, url, &wa.CSVOptions{ContinueOnError: true})
+	if errors.Is(err, wa.ErrNotModified) {
+		return "sheet unchanged since last import", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("fetching sheet: %w", err)
+	}
+
+	clubsMu.Lock()
+	defer clubsMu.Unlock()
+
+	unlock, err := lockData(si.cs)
+	if err != nil {
+		return "", false, err
+	}
+	defer unlock()
+
+	si.cs.SetCollection(guildID)
+	chs, err := si.cs.BulkUpdatePlayers(ctx, res.Players, wa.SyncNone)
+	if err != nil {
+		err = fmt.Errorf("importing players: %w", err)
+	}
+	if err := saveData(si.cs, err); err != nil {
+		return "", false, err
+	}
+
+	var b strings.Builder
+	if err := chs.WriteDiff(&b, false); err != nil {
+		return "", false, fmt.Errorf("summarizing changes: %w", err)
+	}
+	if len(res.Rejected) > 0 {
+		fmt.Fprintf(&b, "%d rows rejected:\n", len(res.Rejected))
+		for i, re := range res.Rejected {
+			if i == _maxRejectedListed {
+				fmt.Fprintf(&b, "...and %d more\n", len(res.Rejected)-i)
+				break
+			}
+			fmt.Fprintf(&b, "%v\n", re)
+		}
+	}
+
+	return b.String(), true, nil
+}
+
+// schedule imports the sheet at url for the guild every interval, replacing any existing schedule.
+// Summaries of changed sheets and failures are passed to post.
+func (si *sheetImports) schedule(guildID, url string, every time.Duration, post func(string)) error {
+	if si.cs.DataLocation() == "" {
+		return errSheetsFileOnly
+	}
+	if every < _minSheetInterval {
+		return fmt.Errorf("interval %v is shorter than the minimum of %v", every, _minSheetInterval)
+	}
+
+	si.unschedule(guildID)
+
+	stop := make(chan struct{})
+
+	si.mu.Lock()
+	si.schedules[guildID] = stop
+	si.mu.Unlock()
+
+	go func() {
+		t := time.NewTicker(every)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+				summary, changed, err := si.run(context.Background(), guildID, url)
+				if err != nil {
+					log.Printf("scheduled sheet import for guild %v: %v", guildID, err)
+					post(fmt.Sprintf("scheduled sheet import failed: %v", err))
+					continue
+				}
+				if changed {
+					post(summary)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// unschedule stops the guild's scheduled import, returning false if there wasn't one.
+func (si *sheetImports) unschedule(guildID string) bool {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	stop, ok := si.schedules[guildID]
+	if !ok {
+		return false
+	}
+
+	close(stop)
+	delete(si.schedules, guildID)
+
+	return true
+}
+
+// handleSheetMessage handles the sheet import commands:
+//
+//	import sheet <url>
+//	schedule sheet <url> <minutes>
+//	unschedule sheet
+func handleSheetMessage(ctx context.Context, si *sheetImports, m *discordgo.MessageCreate, action string, args []string, post func(string)) (any, error) {
+	actions := []string{"import", "schedule", "unschedule"}
+
+	switch action {
+	case actions[0]:
+		if len(args) < 1 {
+			return nil, fmt.Errorf("sheet url required. example: `import sheet https://...`")
+		}
+
+		summary, _, err := si.run(ctx, m.GuildID, args[0])
+		if err != nil {
+			return nil, fmt.Errorf("importing sheet: %w", err)
+		}
+
+		return summary, nil
+	case actions[1]:
+		if len(args) < 2 {
+			return nil, fmt.Errorf("sheet url and interval in minutes required. example: `schedule sheet https://... 60`")
+		}
+
+		mins, err := strconv.Atoi(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("argument for minutes: %q is not a valid number", args[1])
+		}
+
+		if err := si.schedule(m.GuildID, args[0], time.Duration(mins)*time.Minute, post); err != nil {
+			return nil, fmt.Errorf("scheduling sheet import: %w", err)
+		}
+
+		return fmt.Sprintf("importing sheet every %d minutes", mins), nil
+	case actions[2]:
+		if !si.unschedule(m.GuildID) {
+			return nil, fmt.Errorf("no sheet import scheduled")
+		}
+
+		return "scheduled sheet import stopped", nil
+	default:
+		return nil, fmt.Errorf("unknown sheet action %q found. options: %v", action, actions)
+	}
+}
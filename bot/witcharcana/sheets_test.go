@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	wa "github.com/mxygem/witch-arcana"
+)
+
+func TestSheetImportsDB(t *testing.T) {
+	si := newSheetImports(wa.NewClubs(wa.NewDB(&wa.DBConfig{}), false), time.Second)
+
+	_, _, err := si.run(context.Background(), "g1", "https://example.com/players.csv")
+	assert.ErrorIs(t, err, errSheetsFileOnly)
+
+	err = si.schedule("g1", "https://example.com/players.csv", time.Hour, func(string) {})
+	assert.ErrorIs(t, err, errSheetsFileOnly)
+	assert.False(t, si.unschedule("g1"))
+}
+
+func TestSheetImportsPerGuild(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("name,club,level\nFayeee,AZA,18\n"))
+	}))
+	defer srv.Close()
+
+	loc := filepath.Join(t.TempDir(), "clubs.json")
+	require.NoError(t, wa.Save(loc, map[string]*wa.Club{"AZA": {Name: "AZA"}}))
+	cs := wa.NewClubs(nil, false)
+	require.NoError(t, cs.LoadData(loc))
+	si := newSheetImports(cs, time.Second)
+
+	ctx := context.Background()
+	_, changed, err := si.run(ctx, "g1", srv.URL)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	_, changed, err = si.run(ctx, "g1", srv.URL)
+	assert.NoError(t, err)
+	assert.False(t, changed, "unchanged for the guild that imported it")
+
+	_, changed, err = si.run(ctx, "g2", srv.URL)
+	assert.NoError(t, err)
+	assert.True(t, changed, "imported by a guild that hasn't yet")
+}
@@ -0,0 +1,227 @@
+package witcharcana
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+)
+
+// ChangeSet describes the changes made, or that would be made, by a bulk player update.
+type ChangeSet struct {
+	NewClubs   []string         `json:"new_clubs,omitempty"`
+	NewPlayers Players          `json:"new_players,omitempty"`
+	Updated    []*PlayerChange  `json:"updated_players,omitempty"`
+	Moved      []*PlayerMove    `json:"moved_players,omitempty"`
+	Missing    []*MissingPlayer `json:"missing_players,omitempty"`
+}
+
+// PlayerChange lists the fields changed on an existing player.
+type PlayerChange struct {
+	Name   string        `json:"name"`
+	Club   string        `json:"club"`
+	Fields []FieldChange `json:"fields"`
+}
+
+// FieldChange holds the old and new value of a single changed player field.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// PlayerMove records a player moved from one club to another.
+type PlayerMove struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Empty returns true when the change set contains no changes.
+func (chs *ChangeSet) Empty() bool {
+	return len(chs.NewClubs) == 0 && len(chs.NewPlayers) == 0 &&
+		len(chs.Updated) == 0 && len(chs.Moved) == 0 && len(chs.Missing) == 0
+}
+
+// WriteDiff writes a human readable diff of the change set to w, optionally colored for terminals.
+func (chs *ChangeSet) WriteDiff(w io.Writer, color bool) error {
+	line := func(c, format string, a ...any) error {
+		s := fmt.Sprintf(format, a...)
+		if color {
+			s = c + s + colorReset
+		}
+
+		_, err := fmt.Fprintln(w, s)
+		return err
+	}
+
+	for _, c := range chs.NewClubs {
+		if err := line(colorGreen, "+ club %s", c); err != nil {
+			return fmt.Errorf("writing new club: %w", err)
+		}
+	}
+	for _, p := range chs.NewPlayers {
+		if err := line(colorGreen, "+ player %s (%s)%s", p.Name, p.Club, playerSummary(p)); err != nil {
+			return fmt.Errorf("writing new player: %w", err)
+		}
+	}
+	for _, m := range chs.Moved {
+		if err := line(colorCyan, "> player %s: %s -> %s", m.Name, m.From, m.To); err != nil {
+			return fmt.Errorf("writing moved player: %w", err)
+		}
+	}
+	for _, pc := range chs.Updated {
+		if err := line(colorYellow, "~ player %s (%s)", pc.Name, pc.Club); err != nil {
+			return fmt.Errorf("writing updated player: %w", err)
+		}
+		for _, f := range pc.Fields {
+			if err := line(colorYellow, "    %s: %s -> %s", f.Field, f.Old, f.New); err != nil {
+				return fmt.Errorf("writing updated player field: %w", err)
+			}
+		}
+	}
+
+	for _, m := range chs.Missing {
+		if err := line(colorRed, "- player %s (%s): %s", m.Name, m.Club, m.Action); err != nil {
+			return fmt.Errorf("writing missing player: %w", err)
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d new clubs, %d new players, %d moved, %d updated, %d missing\n",
+		len(chs.NewClubs), len(chs.NewPlayers), len(chs.Moved), len(chs.Updated), len(chs.Missing))
+	if err != nil {
+		return fmt.Errorf("writing summary: %w", err)
+	}
+
+	return nil
+}
+
+func playerSummary(p *Player) string {
+	var s string
+	if p.Level > 0 {
+		s += " level: " + strconv.Itoa(p.Level)
+	}
+	if p.Might > 0 {
+		s += " might: " + FormatMight(p.Might)
+	}
+	if p.Location != nil {
+		s += " location: " + p.Location.String()
+	}
+	if p.InHive {
+		s += " in hive"
+	}
+	if len(p.Tags) > 0 {
+		s += " tags: " + p.Tags.String()
+	}
+
+	return s
+}
+
+// playerChanges returns the fields of p that updatePlayer would change using the values from up.
+func playerChanges(p, up *Player) []FieldChange {
+	var fcs []FieldChange
+
+	if up.Level != 0 && up.Level != p.Level {
+		fcs = append(fcs, FieldChange{Field: "level", Old: strconv.Itoa(p.Level), New: strconv.Itoa(up.Level)})
+	}
+	if up.Might != 0 && up.Might != p.Might {
+		fcs = append(fcs, FieldChange{Field: "might", Old: FormatMight(p.Might), New: FormatMight(up.Might)})
+	}
+	if up.Location != nil {
+		nl := Location{X: up.Location.X, Y: up.Location.Y}
+		if p.Location != nil {
+			if nl.X == 0 {
+				nl.X = p.Location.X
+			}
+			if nl.Y == 0 {
+				nl.Y = p.Location.Y
+			}
+		}
+
+		switch {
+		case p.Location == nil && nl.X > 0 && nl.Y > 0:
+			fcs = append(fcs, FieldChange{Field: "location", New: nl.String()})
+		case p.Location != nil && nl != *p.Location:
+			fcs = append(fcs, FieldChange{Field: "location", Old: p.Location.String(), New: nl.String()})
+		}
+	}
+	if up.InHive != p.InHive {
+		fcs = append(fcs, FieldChange{Field: "in_hive", Old: strconv.FormatBool(p.InHive), New: strconv.FormatBool(up.InHive)})
+	}
+	if up.Tags != nil && !up.Tags.equal(p.Tags) {
+		fcs = append(fcs, FieldChange{Field: "tags", Old: p.Tags.String(), New: up.Tags.String()})
+	}
+
+	return fcs
+}
+
+// clone returns a deep copy of the stored clubs. The db connection is intentionally left out so
+// changes made to the copy are never persisted.
+func (cs *Clubs) clone() *Clubs {
+	ccs := &Clubs{
+		clubs:     make(map[string]*Club, len(cs.clubs)),
+		log:       cs.log,
+		dataLoc:   cs.dataLoc,
+		updatedAt: cs.updatedAt,
+	}
+	for k, c := range cs.clubs {
+		ccs.clubs[k] = c.clone()
+	}
+
+	return ccs
+}
+
+func (c *Club) clone() *Club {
+	cc := *c
+	if c.Location != nil {
+		l := *c.Location
+		cc.Location = &l
+	}
+	cc.Players = c.Players.clone()
+	if c.Prospects != nil {
+		cc.Prospects = make(Prospects, len(c.Prospects))
+		for i, pr := range c.Prospects {
+			cpr := *pr
+			cc.Prospects[i] = &cpr
+		}
+	}
+
+	return &cc
+}
+
+func (ps Players) clone() Players {
+	if ps == nil {
+		return nil
+	}
+
+	cps := make(Players, len(ps))
+	for i, p := range ps {
+		cps[i] = p.clone()
+	}
+
+	return cps
+}
+
+func (p *Player) clone() *Player {
+	cp := *p
+	if p.Location != nil {
+		l := *p.Location
+		cp.Location = &l
+	}
+	if p.Snapshots != nil {
+		cp.Snapshots = make([]*MightSnapshot, len(p.Snapshots))
+		for i, ms := range p.Snapshots {
+			cms := *ms
+			cp.Snapshots[i] = &cms
+		}
+	}
+
+	return &cp
+}
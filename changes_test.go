@@ -0,0 +1,143 @@
+package witcharcana
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanBulkUpdate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		clubs    *Clubs
+		players  Players
+		expected *ChangeSet
+	}{
+		{
+			name: "no player data provided",
+			clubs: &Clubs{
+				clubs: map[string]*Club{
+					"CNT": {Name: "CNT", Players: []*Player{{Name: "mxygem"}, {Name: "Hoeb"}}},
+				},
+			},
+			expected: &ChangeSet{},
+		},
+		{
+			name: "unchanged player",
+			clubs: &Clubs{
+				clubs: map[string]*Club{
+					"CNT": {Name: "CNT", Players: []*Player{{Name: "mxygem", Level: 18}}},
+				},
+			},
+			players:  Players{{Name: "mxygem", Level: 18, Club: "CNT"}},
+			expected: &ChangeSet{},
+		},
+		{
+			name: "new clubs, new players, updates and moves",
+			clubs: &Clubs{
+				clubs: map[string]*Club{
+					"CNT": {Name: "CNT", Players: []*Player{
+						{Name: "Hoeb"},
+						{Name: "mxygem", Level: 18, Might: 51848883, Location: &Location{X: 123, Y: 456}},
+					}},
+					"MID": {Name: "MID", Players: []*Player{{Name: "AnsaLovesYou"}}},
+				},
+			},
+			players: Players{
+				{Name: "mxygem", Level: 19, Might: 52100000, Club: "CNT", Location: &Location{X: 123, Y: 457}},
+				{Name: "AnsaLovesYou", Club: "MID", InHive: true},
+				{Name: "Quinoa", Club: "SP", Level: 16},
+				{Name: "Hoeb", Club: "MID"},
+			},
+			expected: &ChangeSet{
+				NewClubs: []string{"SP"},
+				NewPlayers: Players{
+					{Name: "Quinoa", Club: "SP", Level: 16},
+				},
+				Updated: []*PlayerChange{
+					{Name: "mxygem", Club: "CNT", Fields: []FieldChange{
+						{Field: "level", Old: "18", New: "19"},
+						{Field: "might", Old: "51.8m", New: "52.1m"},
+						{Field: "location", Old: "123:456", New: "123:457"},
+					}},
+					{Name: "AnsaLovesYou", Club: "MID", Fields: []FieldChange{
+						{Field: "in_hive", Old: "false", New: "true"},
+					}},
+				},
+				Moved: []*PlayerMove{
+					{Name: "Hoeb", From: "CNT", To: "MID"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := tc.clubs.clone()
+			players := tc.players.clone()
+
+			actual, err := tc.clubs.PlanBulkUpdate(context.Background(), tc.players, SyncNone)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+			// ensure planning never changes stored or provided data
+			assert.Equal(t, before, tc.clubs)
+			assert.Equal(t, players, tc.players)
+		})
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	chs := &ChangeSet{
+		NewClubs: []string{"SP"},
+		NewPlayers: Players{
+			{Name: "Quinoa", Club: "SP", Level: 16, Location: &Location{X: 303, Y: 733}},
+		},
+		Updated: []*PlayerChange{
+			{Name: "mxygem", Club: "CNT", Fields: []FieldChange{{Field: "level", Old: "18", New: "19"}}},
+		},
+		Moved:   []*PlayerMove{{Name: "Hoeb", From: "CNT", To: "MID"}},
+		Missing: []*MissingPlayer{{Name: "M4rs", Club: "CNT", Action: SyncInactive}},
+	}
+
+	testCases := []struct {
+		name     string
+		color    bool
+		expected string
+	}{
+		{
+			name: "plain",
+			expected: "+ club SP\n" +
+				"+ player Quinoa (SP) level: 16 location: 303:733\n" +
+				"> player Hoeb: CNT -> MID\n" +
+				"~ player mxygem (CNT)\n" +
+				"    level: 18 -> 19\n" +
+				"- player M4rs (CNT): inactive\n" +
+				"1 new clubs, 1 new players, 1 moved, 1 updated, 1 missing\n",
+		},
+		{
+			name:  "colored",
+			color: true,
+			expected: colorGreen + "+ club SP" + colorReset + "\n" +
+				colorGreen + "+ player Quinoa (SP) level: 16 location: 303:733" + colorReset + "\n" +
+				colorCyan + "> player Hoeb: CNT -> MID" + colorReset + "\n" +
+				colorYellow + "~ player mxygem (CNT)" + colorReset + "\n" +
+				colorYellow + "    level: 18 -> 19" + colorReset + "\n" +
+				colorRed + "- player M4rs (CNT): inactive" + colorReset + "\n" +
+				"1 new clubs, 1 new players, 1 moved, 1 updated, 1 missing\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+
+			err := chs.WriteDiff(&b, tc.color)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, b.String())
+		})
+	}
+}
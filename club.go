@@ -1,16 +1,27 @@
 package witcharcana
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"sort"
+	"time"
 )
 
 // Clubs is a key value store of clubs with keys being the club's initials.
 type Clubs struct {
-	clubs   map[string]*Club `json:"clubs,omitempty"`
-	log     bool
-	dataLoc string
-	db      *DB
+	clubs     map[string]*Club
+	events    Events
+	schedules Schedules
+	log       bool
+	dataLoc   string
+	updatedAt time.Time
+	db        *DB
+	// now is the clock players are timestamped with when added or updated. Nothing is timestamped
+	// when it's nil.
+	now func() time.Time
 }
 
 // type Clubs map[string]*Club
@@ -20,6 +31,12 @@ type Club struct {
 	Name     string    `json:"name"`
 	Location *Location `json:"location,omitempty"`
 	Players  Players   `json:"players,omitempty"`
+	// Capacity is the most players the club can have in game. 0 means it isn't known.
+	Capacity    int         `json:"capacity,omitempty"`
+	Recruitment Recruitment `json:"recruitment,omitempty"`
+	Prospects   Prospects   `json:"prospects,omitempty"`
+	Tags        Tags        `json:"tags,omitempty"`
+	Notes       string      `json:"notes,omitempty"`
 }
 
 // NewClubs returns a pointer to a new Clubs object.
@@ -28,16 +45,18 @@ func NewClubs(db *DB, log bool) *Clubs {
 		clubs: map[string]*Club{},
 		log:   log,
 		db:    db,
+		now:   time.Now,
 	}
 }
 
-// NewClub returns a pointer to a new individual club.
+// NewClub returns a pointer to a new individual club. The club has no location when both x and y
+// are zero.
 func NewClub(name string, x, y int) *Club {
 	c := &Club{
 		Name: name,
 	}
 
-	if x > 0 && y > 0 {
+	if x != 0 || y != 0 {
 		c.Location = &Location{X: x, Y: y}
 	}
 
@@ -63,9 +82,35 @@ func (cs *Clubs) LoadData(filename string) error {
 
 	cs.dataLoc = filename
 
+	cs.updatedAt = csd.updatedAt
 	if csd.clubs != nil {
 		cs.clubs = csd.clubs
 	}
+	cs.events = csd.events
+	cs.schedules = csd.schedules
+
+	return nil
+}
+
+// Reload reads the data file again so changes saved by others since it was loaded are seen. Any
+// unsaved changes are discarded. Nothing is done when stored in a database.
+func (cs *Clubs) Reload() error {
+	if cs.db != nil {
+		return nil
+	}
+
+	csd, err := loadData(cs.dataLoc)
+	if err != nil {
+		return fmt.Errorf("reloading data: %w", err)
+	}
+
+	cs.updatedAt = csd.updatedAt
+	cs.clubs = csd.clubs
+	if cs.clubs == nil {
+		cs.clubs = map[string]*Club{}
+	}
+	cs.events = csd.events
+	cs.schedules = csd.schedules
 
 	return nil
 }
@@ -79,22 +124,74 @@ func loadData(filename string) (*Clubs, error) {
 	return csd, nil
 }
 
+// Save writes the clubs, events and schedules back to the data file they were loaded from. ErrDataChanged is returned
+// without saving when the file has been saved by someone else since it was loaded. Nothing is done
+// when stored in a database as changes are written as they're made.
+func (cs *Clubs) Save() error {
+	if cs.db != nil {
+		return nil
+	}
+
+	cur, err := open(cs.dataLoc)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("checking data file: %w", err)
+	case !cur.updatedAt.Equal(cs.updatedAt):
+		return fmt.Errorf("saving to %q: %w", cs.dataLoc, ErrDataChanged)
+	}
+
+	t, err := save(cs.dataLoc, &dataFile{Clubs: cs.clubs, Events: cs.events, Schedules: cs.schedules})
+	if err != nil {
+		return fmt.Errorf("saving to %q: %w", cs.dataLoc, err)
+	}
+	cs.updatedAt = t
+
+	return nil
+}
+
 // DataLocation returns the file location of the configured data file on disk.
 func (cs *Clubs) DataLocation() string {
 	return cs.dataLoc
 }
 
+// UpdatedAt returns when the loaded data file was last saved. It's zero for files saved before the
+// data file was versioned.
+func (cs *Clubs) UpdatedAt() time.Time {
+	return cs.updatedAt
+}
+
 // All returns all clubs
 func (cs *Clubs) All() map[string]*Club {
 	return cs.clubs
 }
 
+// List returns every club by name, reading them from the database when one is used. Unlike All it
+// works with either backend.
+func (cs *Clubs) List(ctx context.Context) (map[string]*Club, error) {
+	if cs.db == nil {
+		return cs.clubs, nil
+	}
+
+	dcs, err := cs.db.Clubs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing clubs: %w", err)
+	}
+
+	clubs := make(map[string]*Club, len(dcs))
+	for _, c := range dcs {
+		clubs[c.Name] = c
+	}
+
+	return clubs, nil
+}
+
 // Club returns a single club by name if found.
-func (cs *Clubs) Club(name string) (*Club, error) {
+func (cs *Clubs) Club(ctx context.Context, name string) (*Club, error) {
 	var c *Club
 
 	if cs.db != nil {
-		dc, err := cs.db.Get(name)
+		dc, err := cs.db.Get(ctx, name)
 		if err != nil {
 			return nil, fmt.Errorf("getting club: %w", err)
 		}
@@ -103,7 +200,7 @@ func (cs *Clubs) Club(name string) (*Club, error) {
 	} else {
 		lc := club(cs.clubs, name)
 		if lc == nil {
-			return nil, fmt.Errorf("no club %q found", name)
+			return nil, fmt.Errorf("%w: %q", ErrClubNotFound, name)
 		}
 		c = lc
 	}
@@ -111,6 +208,31 @@ func (cs *Clubs) Club(name string) (*Club, error) {
 	return c, nil
 }
 
+// selectClubs returns every club ordered by name or, when clubName is provided, only that club.
+func selectClubs(ctx context.Context, cs *Clubs, clubName string) ([]*Club, error) {
+	if clubName != "" {
+		c, err := cs.Club(ctx, clubName)
+		if err != nil {
+			return nil, fmt.Errorf("getting club: %w", err)
+		}
+
+		return []*Club{c}, nil
+	}
+
+	all, err := cs.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	clubs := make([]*Club, 0, len(all))
+	for _, c := range all {
+		clubs = append(clubs, c)
+	}
+	sort.Slice(clubs, func(i, j int) bool { return clubs[i].Name < clubs[j].Name })
+
+	return clubs, nil
+}
+
 func club(cs map[string]*Club, name string) *Club {
 	if c, ok := cs[name]; ok {
 		return c
@@ -120,18 +242,18 @@ func club(cs map[string]*Club, name string) *Club {
 }
 
 // CreateClub uses the provided club information to create a new club.
-func (cs *Clubs) CreateClub(c *Club) error {
+func (cs *Clubs) CreateClub(ctx context.Context, c *Club) error {
 	// todo: return newly created club
-	return createClub(cs, c)
+	return createClub(ctx, cs, c)
 }
 
-func createClub(cs *Clubs, c *Club) error {
-	if c.Name == "" {
-		return fmt.Errorf("club name required")
+func createClub(ctx context.Context, cs *Clubs, c *Club) error {
+	if err := c.Validate(); err != nil {
+		return err
 	}
 
 	if oc := club(cs.clubs, c.Name); oc != nil {
-		return fmt.Errorf("club %q already exists", c.Name)
+		return fmt.Errorf("club %q %w", c.Name, ErrAlreadyExists)
 	}
 
 	if cs.db == nil {
@@ -139,7 +261,7 @@ func createClub(cs *Clubs, c *Club) error {
 		return nil
 	}
 
-	id, err := cs.db.Create(c)
+	id, err := cs.db.Create(ctx, c)
 	if err != nil {
 		return fmt.Errorf("creating club: %w", err)
 	}
@@ -151,8 +273,8 @@ func createClub(cs *Clubs, c *Club) error {
 }
 
 // UpdateClub updates a given club if found with the provided information.
-func (cs *Clubs) UpdateClub(uc *Club) (*Club, error) {
-	c, err := updateClub(cs, uc)
+func (cs *Clubs) UpdateClub(ctx context.Context, uc *Club) (*Club, error) {
+	c, err := updateClub(ctx, cs, uc)
 	if err != nil {
 		return nil, fmt.Errorf("updating club: %w", err)
 	}
@@ -160,23 +282,25 @@ func (cs *Clubs) UpdateClub(uc *Club) (*Club, error) {
 	return c, nil
 }
 
-func updateClub(cs *Clubs, uc *Club) (*Club, error) {
-	if uc.Name == "" {
-		return nil, fmt.Errorf("updated club information must contain a name")
+func updateClub(ctx context.Context, cs *Clubs, uc *Club) (*Club, error) {
+	if uc.Location == nil && uc.Capacity == 0 && uc.Recruitment == "" {
+		return nil, fmt.Errorf("updated club information must contain a location, capacity or recruitment state")
 	}
-	if uc.Location.X == 0 || uc.Location.Y == 0 {
-		return nil, fmt.Errorf("no location values can be zero. got x: %d y: %d", uc.Location.X, uc.Location.Y)
+	if err := uc.Validate(); err != nil {
+		return nil, err
 	}
 
-	c := club(cs.clubs, uc.Name)
-	if c == nil {
-		return nil, fmt.Errorf("no club %q found", uc.Name)
+	c, err := cs.Club(ctx, uc.Name)
+	if err != nil {
+		return nil, err
 	}
 
-	if c.Location == nil {
+	switch {
+	case uc.Location == nil:
+	case c.Location == nil:
 		l := &Location{X: uc.Location.X, Y: uc.Location.Y}
 		c.Location = l
-	} else {
+	default:
 		if c.Location.X != uc.Location.X {
 			c.Location.X = uc.Location.X
 		}
@@ -184,27 +308,47 @@ func updateClub(cs *Clubs, uc *Club) (*Club, error) {
 			c.Location.Y = uc.Location.Y
 		}
 	}
+	if uc.Capacity != 0 {
+		c.Capacity = uc.Capacity
+	}
+	if uc.Recruitment != "" {
+		c.Recruitment = uc.Recruitment
+	}
+
+	if cs.db != nil {
+		if err := cs.db.Update(ctx, c); err != nil {
+			return nil, fmt.Errorf("updating db: %w", err)
+		}
+	}
 
 	return c, nil
 }
 
 // RemoveClub removes a club by name and all its associated data. (including players)
-func (cs *Clubs) RemoveClub(name string) error {
-	if err := removeClub(cs, name); err != nil {
+func (cs *Clubs) RemoveClub(ctx context.Context, name string) error {
+	if err := removeClub(ctx, cs, name); err != nil {
 		return fmt.Errorf("removing club: %w", err)
 	}
 
 	return nil
 }
 
-func removeClub(cs *Clubs, name string) error {
+func removeClub(ctx context.Context, cs *Clubs, name string) error {
 	if name == "" {
 		return fmt.Errorf("club name required")
 	}
 
+	if cs.db != nil {
+		if err := cs.db.Delete(ctx, name); err != nil {
+			return fmt.Errorf("deleting club: %w", err)
+		}
+
+		return nil
+	}
+
 	c := club(cs.clubs, name)
 	if c == nil {
-		return fmt.Errorf("no club %q found", name)
+		return fmt.Errorf("%w: %q", ErrClubNotFound, name)
 	}
 
 	delete(cs.clubs, name)
@@ -224,6 +368,11 @@ func maybeMakeClub(cs *Clubs, name string) *Club {
 	return nc
 }
 
+// SetCollection switches the mongo collection used, doing nothing when stored in a data file.
 func (cs *Clubs) SetCollection(collection string) {
+	if cs.db == nil {
+		return
+	}
+
 	cs.db.setCollection(collection)
 }
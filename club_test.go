@@ -1,16 +1,21 @@
 package witcharcana
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNewClubs(t *testing.T) {
-	expected := &Clubs{log: true}
+	expected := &Clubs{clubs: map[string]*Club{}, log: true}
 
-	assert.Equal(t, expected, NewClubs(nil, true))
+	actual := NewClubs(nil, true)
+	assert.NotNil(t, actual.now)
+	actual.now = nil
+	assert.Equal(t, expected, actual)
 }
 
 func TestNewClub(t *testing.T) {
@@ -79,6 +84,22 @@ func TestLoadData(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "versioned file",
+			filename: "./testdata/clubs_v1.json",
+			expected: &Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee", Level: 18}}},
+				},
+				updatedAt: time.Date(2023, 6, 20, 18, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "newer version",
+			filename: "./testdata/future.json",
+			expectedErr: fmt.Errorf("loading club data from file: \"./testdata/future.json\": " +
+				"unmarshaling: data file version 99 is newer than the supported version 1"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -146,7 +167,7 @@ func TestCsClub(t *testing.T) {
 					"SP":  {Name: "SP"},
 					"MID": {Name: "MID"},
 				}},
-			expectedErr: fmt.Errorf(`no club "KMA" found`),
+			expectedErr: fmt.Errorf(`club not found: "KMA"`),
 		},
 		{
 			name:     "club found",
@@ -162,7 +183,7 @@ func TestCsClub(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			actual, err := tc.clubs.Club(tc.clubName)
+			actual, err := tc.clubs.Club(context.Background(), tc.clubName)
 
 			assert.Equal(t, tc.expected, actual)
 			if tc.expectedErr != nil {
@@ -201,7 +222,7 @@ func TestCsCreateClub(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := tc.clubs.CreateClub(tc.club)
+			err := tc.clubs.CreateClub(context.Background(), tc.club)
 
 			if tc.expected != nil {
 				assert.Equal(t, tc.expected, tc.clubs)
@@ -236,7 +257,7 @@ func TestCreateClub(t *testing.T) {
 		{
 			name:        "no name given",
 			club:        &Club{Location: &Location{X: 123, Y: 456}},
-			expectedErr: fmt.Errorf("club name required"),
+			expectedErr: fmt.Errorf("invalid club: name required"),
 		},
 		{
 			name: "successful creation",
@@ -256,7 +277,7 @@ func TestCreateClub(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := createClub(tc.clubs, tc.club)
+			err := createClub(context.Background(), tc.clubs, tc.club)
 
 			if tc.expected != nil {
 				assert.Equal(t, tc.expected, tc.clubs)
@@ -282,17 +303,17 @@ func TestCsUpdateClub(t *testing.T) {
 		{
 			name:        "no club name in updated info",
 			updated:     &Club{Location: &Location{X: 321, Y: 876}},
-			expectedErr: fmt.Errorf("updating club: updated club information must contain a name"),
+			expectedErr: fmt.Errorf("updating club: invalid club: name required"),
 		},
 		{
 			name:        "invalid x coordinate",
 			updated:     &Club{Name: "404", Location: &Location{X: 0, Y: 876}},
-			expectedErr: fmt.Errorf("updating club: no location values can be zero. got x: 0 y: 876"),
+			expectedErr: fmt.Errorf(`updating club: invalid club "404": location.x must be between 1 and 1000. got 0`),
 		},
 		{
 			name:        "invalid y coordinate",
 			updated:     &Club{Name: "404", Location: &Location{X: 123, Y: 0}},
-			expectedErr: fmt.Errorf("updating club: no location values can be zero. got x: 123 y: 0"),
+			expectedErr: fmt.Errorf(`updating club: invalid club "404": location.y must be between 1 and 1000. got 0`),
 		},
 		{
 			name:    "club doesn't exist",
@@ -303,7 +324,7 @@ func TestCsUpdateClub(t *testing.T) {
 					"MID": {Name: "MID", Players: []*Player{{Name: "Menace"}}},
 				},
 			},
-			expectedErr: fmt.Errorf(`updating club: no club "DYR" found`),
+			expectedErr: fmt.Errorf(`updating club: club not found: "DYR"`),
 		},
 		{
 			name:    "location added",
@@ -369,7 +390,7 @@ func TestCsUpdateClub(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			actual, err := tc.clubs.UpdateClub(tc.updated)
+			actual, err := tc.clubs.UpdateClub(context.Background(), tc.updated)
 
 			assert.Equal(t, tc.expected, actual)
 			if tc.expected != nil {
@@ -396,17 +417,17 @@ func TestUpdateClub(t *testing.T) {
 		{
 			name:        "no club name in updated info",
 			updated:     &Club{Location: &Location{X: 321, Y: 876}},
-			expectedErr: fmt.Errorf("updated club information must contain a name"),
+			expectedErr: fmt.Errorf("invalid club: name required"),
 		},
 		{
 			name:        "invalid x coordinate",
 			updated:     &Club{Name: "404", Location: &Location{X: 0, Y: 876}},
-			expectedErr: fmt.Errorf("no location values can be zero. got x: 0 y: 876"),
+			expectedErr: fmt.Errorf(`invalid club "404": location.x must be between 1 and 1000. got 0`),
 		},
 		{
 			name:        "invalid y coordinate",
 			updated:     &Club{Name: "404", Location: &Location{X: 123, Y: 0}},
-			expectedErr: fmt.Errorf("no location values can be zero. got x: 123 y: 0"),
+			expectedErr: fmt.Errorf(`invalid club "404": location.y must be between 1 and 1000. got 0`),
 		},
 		{
 			name:    "club doesn't exist",
@@ -417,7 +438,7 @@ func TestUpdateClub(t *testing.T) {
 					"MID": {Name: "MID", Players: []*Player{{Name: "Menace"}}},
 				},
 			},
-			expectedErr: fmt.Errorf(`no club "DYR" found`),
+			expectedErr: fmt.Errorf(`club not found: "DYR"`),
 		},
 		{
 			name:    "location added",
@@ -479,11 +500,31 @@ func TestUpdateClub(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "nothing to update",
+			updated:     &Club{Name: "SP"},
+			expectedErr: fmt.Errorf("updated club information must contain a location, capacity or recruitment state"),
+		},
+		{
+			name:    "capacity and recruitment updated",
+			updated: &Club{Name: "SP", Capacity: 30, Recruitment: RecruitmentInvite},
+			clubs: &Clubs{
+				clubs: map[string]*Club{
+					"SP": {Name: "SP", Location: &Location{X: 321, Y: 876}, Recruitment: RecruitmentOpen},
+				},
+			},
+			expected: &Club{Name: "SP", Location: &Location{X: 321, Y: 876}, Capacity: 30, Recruitment: RecruitmentInvite},
+			expectedClubs: &Clubs{
+				clubs: map[string]*Club{
+					"SP": {Name: "SP", Location: &Location{X: 321, Y: 876}, Capacity: 30, Recruitment: RecruitmentInvite},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			actual, err := updateClub(tc.clubs, tc.updated)
+			actual, err := updateClub(context.Background(), tc.clubs, tc.updated)
 
 			assert.Equal(t, tc.expected, actual)
 			if tc.expected != nil {
@@ -514,7 +555,7 @@ func TestCsRemoveClub(t *testing.T) {
 					"SP": {Name: "SP", Location: &Location{X: 246, Y: 135}},
 				},
 			},
-			expectedErr: fmt.Errorf(`removing club: no club "CCC" found`),
+			expectedErr: fmt.Errorf(`removing club: club not found: "CCC"`),
 		},
 		{
 			name:     "successful delete",
@@ -537,7 +578,7 @@ func TestCsRemoveClub(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			cs := *tc.clubs
 
-			err := tc.clubs.RemoveClub(tc.clubName)
+			err := tc.clubs.RemoveClub(context.Background(), tc.clubName)
 
 			if tc.expectedErr != nil {
 				// ensure original data is not changed on err
@@ -577,7 +618,7 @@ func TestRemoveClub(t *testing.T) {
 					"SP": {Name: "SP", Location: &Location{X: 246, Y: 135}},
 				},
 			},
-			expectedErr: fmt.Errorf(`no club "CCC" found`),
+			expectedErr: fmt.Errorf(`club not found: "CCC"`),
 		},
 		{
 			name:     "successful delete",
@@ -600,7 +641,7 @@ func TestRemoveClub(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			cs := *tc.clubs
 
-			err := removeClub(tc.clubs, tc.clubName)
+			err := removeClub(context.Background(), tc.clubs, tc.clubName)
 
 			if tc.expectedErr != nil {
 				// ensure original data is not changed on err
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	wa "github.com/mxygem/witch-arcana"
+)
+
+// backup handles the backup subcommands for the data file at loc:
+//
+//	backup list
+//	backup restore <name>
+func backup(loc string, args []string) {
+	if len(args) == 0 {
+		usagef("missing backup subcommand. options: [list restore]")
+	}
+
+	switch args[0] {
+	case "list":
+		bs, err := wa.Backups(loc)
+		if err != nil {
+			fatal("listing backups", err)
+		}
+
+		if len(bs) == 0 {
+			fmt.Println("no backups found")
+			return
+		}
+		for _, b := range bs {
+			fmt.Printf("%s\t%s\n", b.Name, b.Time.Local().Format(time.RFC1123))
+		}
+	case "restore":
+		if len(args) < 2 {
+			usagef("missing name of backup to restore")
+		}
+
+		if err := wa.RestoreBackup(loc, args[1]); err != nil {
+			fatal("restoring backup", err)
+		}
+
+		fmt.Printf("restored %s\n", args[1])
+	default:
+		usagef("unknown backup subcommand: %q", args[0])
+	}
+}
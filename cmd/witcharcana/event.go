@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	wa "github.com/mxygem/witch-arcana"
+)
+
+// eventOptions are the flags used by the event commands.
+type eventOptions struct {
+	id, eventType, date, clubs, player, score string
+}
+
+// event handles the event commands: create, attend, report and get, which lists every event or
+// shows the one chosen with --event.
+func event(ctx context.Context, cs *wa.Clubs, args []string, opts *eventOptions, show func(any)) {
+	if len(args) == 0 {
+		usagef("missing event subcommand. options: [get create attend report]")
+	}
+
+	var et wa.EventType
+	if opts.eventType != "" {
+		t, err := wa.ParseEventType(opts.eventType)
+		if err != nil {
+			usagef("%v", err)
+		}
+		et = t
+	}
+
+	switch args[0] {
+	case "get":
+		if opts.id == "" {
+			show(cs.Events())
+			return
+		}
+
+		e, err := cs.Event(opts.id)
+		if err != nil {
+			fatal("getting event", err)
+		}
+
+		show(e)
+	case "create":
+		if et == "" {
+			usagef("--type is required")
+		}
+
+		date := time.Now()
+		if opts.date != "" {
+			d, err := time.Parse(wa.EventDateLayout, opts.date)
+			if err != nil {
+				usagef("invalid --date %q. expected YYYY-MM-DD", opts.date)
+			}
+			date = d
+		}
+
+		var clubs []string
+		if opts.clubs != "" {
+			for _, c := range strings.Split(opts.clubs, ",") {
+				clubs = append(clubs, strings.TrimSpace(c))
+			}
+		}
+
+		e := wa.NewEvent(et, date, clubs...)
+		if err := cs.CreateEvent(ctx, e); err != nil {
+			fatal("creating event", err)
+		}
+
+		show(e)
+	case "attend":
+		var score int64
+		if opts.score != "" {
+			s, err := strconv.ParseInt(opts.score, 10, 64)
+			if err != nil {
+				usagef("invalid --score %q", opts.score)
+			}
+			score = s
+		}
+
+		if _, err := cs.RecordAttendance(ctx, opts.id, opts.player, score); err != nil {
+			fatal("recording attendance", err)
+		}
+
+		e, err := cs.Event(opts.id)
+		if err != nil {
+			fatal("getting event", err)
+		}
+
+		show(e)
+	case "report":
+		if strings.Contains(opts.clubs, ",") {
+			usagef("event report takes a single --club. got %q", opts.clubs)
+		}
+
+		pa, err := cs.Participation(ctx, &wa.ParticipationOptions{Club: opts.clubs, Type: et})
+		if err != nil {
+			fatal("reporting participation", err)
+		}
+
+		show(pa)
+	default:
+		usagef("unknown event subcommand: %q", args[0])
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+
+	wa "github.com/mxygem/witch-arcana"
+)
+
+// Exit codes let scripts tell why a command failed.
+const (
+	exitError    = 1 // any other failure
+	exitUsage    = 2 // missing or unknown commands
+	exitNotFound = 3 // the club, player, prospect, event, backup or data file doesn't exist
+	exitExists   = 4 // the club, player, prospect, event or data file already exists
+	exitInvalid  = 5 // the club or player details are invalid
+	exitConflict = 6 // the data file is locked or was changed by another run
+)
+
+// fatal logs msg with err and exits with the code matching the kind of error.
+func fatal(msg string, err error) {
+	log.Printf("%s: %v", msg, err)
+	os.Exit(exitCode(err))
+}
+
+func exitCode(err error) int {
+	var ve *wa.ValidationError
+	switch {
+	case errors.Is(err, wa.ErrClubNotFound), errors.Is(err, wa.ErrPlayerNotFound),
+		errors.Is(err, wa.ErrProspectNotFound), errors.Is(err, wa.ErrEventNotFound), errors.Is(err, fs.ErrNotExist):
+		return exitNotFound
+	case errors.Is(err, wa.ErrAlreadyExists):
+		return exitExists
+	case errors.As(err, &ve):
+		return exitInvalid
+	case errors.Is(err, wa.ErrLocked), errors.Is(err, wa.ErrDataChanged):
+		return exitConflict
+	default:
+		return exitError
+	}
+}
+
+// usagef logs the misuse of a command and exits.
+func usagef(format string, args ...any) {
+	log.Printf(format, args...)
+	os.Exit(exitUsage)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+
+	wa "github.com/mxygem/witch-arcana"
+)
+
+// export writes the players of all clubs, or a single club if provided, to loc in the given format.
+// Only players with every tag given are written.
+func export(ctx context.Context, cs *wa.Clubs, clubName, format, loc string, tags ...string) {
+	f, err := wa.ParseExportFormat(format)
+	if err != nil {
+		fatal("exporting", err)
+	}
+
+	rows, err := cs.ExportRows(ctx, clubName, tags...)
+	if err != nil {
+		fatal("exporting", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if loc != "" {
+		of, err := os.Create(loc)
+		if err != nil {
+			fatal("creating export file", err)
+		}
+		defer of.Close()
+
+		w = of
+	}
+
+	if err := wa.WriteExport(w, f, rows); err != nil {
+		fatal("writing export", err)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+
+	wa "github.com/mxygem/witch-arcana"
+)
+
+// initData handles the init command, creating an empty data file at loc.
+func initData(loc string) {
+	if err := wa.InitDataFile(loc); err != nil {
+		fatal("initializing data file", err)
+	}
+
+	fmt.Printf("created %s\n", loc)
+}
+
+// initConfig handles the init config command, writing the current settings to a new config file at
+// loc or witcharcana.yaml when loc is empty.
+func initConfig(loc string, cfg *wa.Config) {
+	if loc == "" {
+		loc = wa.ConfigFile
+	}
+
+	if err := wa.WriteConfig(loc, cfg); err != nil {
+		fatal("initializing config", err)
+	}
+
+	fmt.Printf("created %s\n", loc)
+}
+
+// offerInit checks the data file at loc exists, offering to create it when it doesn't. Creating it is
+// assumed when yes is set, otherwise the user is asked if stdin is a terminal.
+func offerInit(loc string, yes bool) {
+	_, err := os.Stat(loc)
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		fatal("checking data file", err)
+	}
+
+	if !yes {
+		if !interactive() {
+			log.Printf("data file %q doesn't exist. create it with: witcharcana --data %s init", loc, loc)
+			os.Exit(exitNotFound)
+		}
+
+		fmt.Fprintf(os.Stderr, "data file %q doesn't exist. create it? [y/N] ", loc)
+		ans, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			ans = ""
+		}
+		if a := strings.ToLower(strings.TrimSpace(ans)); a != "y" && a != "yes" {
+			log.Printf("data file %q doesn't exist", loc)
+			os.Exit(exitNotFound)
+		}
+	}
+
+	initData(loc)
+}
+
+// interactive returns true when stdin is a terminal rather than a pipe or file.
+func interactive() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
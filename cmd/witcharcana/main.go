@@ -1,161 +1,654 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"time"
 
 	wa "github.com/mxygem/witch-arcana"
 	flag "github.com/spf13/pflag"
 )
 
-const (
-	fileLoc = "clubs.json"
-)
-
 var (
 	shouldLog bool
+	// writeActions are the actions that change data and need saving.
+	writeActions = []string{"add", "update", "remove", "move", "promote", "demote"}
 )
 
 func main() {
 	var clubName, newClubName, name string
-	var dataLoc, csvLoc string
-	var level, x, y int
-	var allClubs bool
+	var dataLoc, csvLoc, rejectedLoc, columnsLoc, delimiter string
+	var columns map[string]string
+	var level, x, y, top, capacity, days, snapshots int
+	var fetchTimeout, lockTimeout time.Duration
+	var allClubs, dryRun, apply, noColor, continueOnError, strictClubs, yes bool
+	var output, syncClub, format, outLoc, configLoc, templateName, might, rankBy string
+	var recruitment, contact, tags, notes, role string
+	var eventID, eventType, eventDate, score string
 
-	flag.StringVarP(&dataLoc, "data", "d", fileLoc, "location of imported clubs file")
-	flag.StringVarP(&clubName, "club", "c", "", "name of player's club")
+	flag.StringVar(&configLoc, "config", "", "config file to read settings from. defaults to witcharcana.yaml if found")
+	flag.String("backend", string(wa.BackendFile), "where club data is stored. only file is supported by the cli")
+	flag.String("template-dir", "", "directory of templates overriding the built in ones")
+	flag.StringVar(&templateName, "template", "", "render text output with the named template, ie: roster")
+	flag.StringVarP(&dataLoc, "data", "d", wa.DefaultConfig().Data, "location of imported clubs file")
+	flag.StringVarP(&clubName, "club", "c", "", "name of player's club, or comma separated clubs taking part in a new event")
 	flag.StringVarP(&newClubName, "new-club", "m", "", "name of player's new club")
 	flag.StringVarP(&name, "name", "n", "", "name of player")
 	flag.IntVarP(&level, "level", "l", 0, "player's level")
+	flag.StringVar(&might, "might", "", "player's might, in full or compact, ie: 70.2m")
 	flag.IntVarP(&x, "pos-x", "x", 0, "player's x position")
 	flag.IntVarP(&y, "pos-y", "y", 0, "player's position")
-	flag.StringVar(&csvLoc, "csv", "", "import player data via csv")
+	flag.IntVar(&capacity, "capacity", 0, "most players the club can have")
+	flag.StringVar(&recruitment, "recruitment", "", "club's recruitment state: open, invite or closed")
+	flag.StringVar(&contact, "contact", "", "notes on contacting a prospect")
+	flag.StringVarP(&tags, "tag", "t", "", "comma separated tags to add or remove, or to filter players by when getting or exporting")
+	flag.StringVar(&role, "role", "", "player's role in their club: leader, officer, member or a custom role")
+	flag.StringVar(&notes, "notes", "", "notes on a player, or on a club when no player is named. empty clears them")
+	flag.StringVar(&eventID, "event", "", "id of the event, ie: club-war-2026-10-19-AZA")
+	flag.StringVar(&eventType, "type", "", "event type, ie: club-war or hive-defense")
+	flag.StringVar(&eventDate, "date", "", "event date as YYYY-MM-DD. defaults to today")
+	flag.StringVar(&score, "score", "", "player's score in an event")
+	flag.StringVar(&csvLoc, "csv", "", "import player data via csv file or http(s) url")
+	flag.DurationVar(&fetchTimeout, "fetch-timeout", 30*time.Second, "timeout when importing a csv from a url")
+	flag.StringToStringVar(&columns, "column", nil, "map a csv header onto a player field, ie: Player=name. IGN, Guild, Lvl and Coords are known")
+	flag.StringVar(&columnsLoc, "columns-file", "", "json file mapping csv headers onto player fields")
+	flag.StringVar(&delimiter, "delimiter", "", "csv field delimiter: comma, tab or semicolon. detected when unset")
+	flag.BoolVar(&continueOnError, "continue-on-error", false, "skip invalid csv rows instead of stopping")
+	flag.StringVar(&rejectedLoc, "rejected", "", "write invalid csv rows to the given file")
+	flag.BoolVar(&strictClubs, "strict-clubs", false, "reject csv rows for clubs that don't already exist")
+	flag.BoolVar(&dryRun, "dry-run", false, "show the changes a csv import would make without saving them")
+	flag.BoolVar(&apply, "apply", false, "apply the changes shown by --dry-run or --sync-club, or mark reported inactive players")
+	flag.IntVar(&days, "days", 14, "report players whose stats haven't changed in this many days. 0 skips the check")
+	flag.IntVar(&snapshots, "snapshots", 0, "report players whose might hasn't grown across this many snapshots. 0 skips the check")
+	flag.StringVar(&syncClub, "sync-club", "", "handle players missing from imported club rosters: inactive, unassign or delete")
+	flag.StringVarP(&output, "output", "o", "text", "output format: text or json. csv import changes are shown as a diff as text")
+	flag.BoolVar(&noColor, "no-color", false, "disable colored diff output")
+	flag.StringVar(&format, "format", "csv", "export format: csv, xlsx or jsonl")
+	flag.StringVar(&outLoc, "out", "", "file to export to. defaults to stdout")
+	flag.StringVar(&rankBy, "by", string(wa.RankByMight), "leaderboard ranking: might or level")
+	flag.IntVar(&top, "top", 20, "number of players on the leaderboard. 0 ranks every player")
 	flag.BoolVarP(&allClubs, "all", "a", false, "get all clubs")
+	flag.DurationVar(&lockTimeout, "lock-timeout", 10*time.Second, "how long to wait for other runs changing the data file")
+	flag.BoolVar(&yes, "yes", false, "create the data file without asking when it doesn't exist")
 	flag.BoolVarP(&shouldLog, "verbose", "v", false, "enable log output")
 	flag.Parse()
 
 	args := flag.Args()
-	if len(args) < 2 {
-		log.Println("missing required command & subcommand")
+	if len(args) == 0 {
+		log.Println("missing required command")
 		flag.Usage()
+		os.Exit(exitUsage)
 	}
 
-	resource := args[1]
-	action := args[0]
+	cfg := configure(configLoc)
+	if cfg.Backend != wa.BackendFile {
+		usagef("the %s backend isn't supported by the cli", cfg.Backend)
+	}
+	dataLoc, shouldLog = cfg.Data, cfg.Verbose
+
+	ts, err := wa.ParseTags(tags)
+	if err != nil {
+		usagef("%v", err)
+	}
+
+	switch args[0] {
+	case "init":
+		if len(args) > 1 && args[1] == "config" {
+			initConfig(configLoc, cfg)
+			return
+		}
+		initData(dataLoc)
+		return
+	case "backup":
+		// backups can be restored over a missing data file.
+	default:
+		offerInit(dataLoc, yes)
+	}
+
+	if writes(args) || args[0] == "inactive" && apply {
+		// held until exit so concurrent runs changing the same data file take turns.
+		l, err := wa.LockFile(dataLoc, lockTimeout)
+		if err != nil {
+			fatal("locking data file", err)
+		}
+		defer l.Unlock()
+	}
+
+	// backups are handled before loading so a broken data file can be restored.
+	if args[0] == "backup" {
+		backup(dataLoc, args[1:])
+		return
+	}
+
+	// interrupting stops long running work such as fetching csvs.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	tmpls := wa.NewTemplates(cfg.TemplateDir)
+	// show prints d as json when asked and otherwise renders it with the chosen or default template.
+	show := func(d any) {
+		if output == "json" {
+			print(d)
+			return
+		}
+
+		var err error
+		if templateName != "" {
+			err = tmpls.Execute(os.Stdout, templateName, d)
+		} else {
+			err = tmpls.Render(os.Stdout, d)
+		}
+		if err != nil {
+			fatal("rendering", err)
+		}
+	}
 
 	cs := wa.NewClubs(nil, shouldLog)
 	if err := cs.LoadData(dataLoc); err != nil {
-		log.Fatalf("failed to load data: %v", err)
+		fatal("failed to load data", err)
+	}
+
+	switch args[0] {
+	case "export":
+		export(ctx, cs, clubName, format, outLoc, ts...)
+		return
+	case "stats":
+		st, err := cs.Stats(ctx, clubName)
+		if err != nil {
+			fatal("getting stats", err)
+		}
+
+		show(st)
+		return
+	case "leaderboard":
+		by, err := wa.ParseRankBy(rankBy)
+		if err != nil {
+			usagef("%v", err)
+		}
+
+		lb, err := cs.Leaderboard(ctx, by, top, clubName)
+		if err != nil {
+			fatal("getting leaderboard", err)
+		}
+
+		show(lb)
+		return
+	case "event":
+		opts := &eventOptions{id: eventID, eventType: eventType, date: eventDate, clubs: clubName, player: name, score: score}
+		event(ctx, cs, args[1:], opts, show)
+		if writes(args) {
+			save(cs)
+		}
+		return
+	case "inactive":
+		report, err := cs.InactivityReport(ctx, &wa.InactivityOptions{Days: days, Snapshots: snapshots, Club: clubName})
+		if err != nil {
+			fatal("reporting inactive players", err)
+		}
+
+		show(report)
+		if !apply || len(report) == 0 {
+			return
+		}
+
+		names := make([]string, len(report))
+		for i, ip := range report {
+			names[i] = ip.Name
+		}
+		chs, err := cs.MarkInactive(ctx, names...)
+		if err != nil {
+			fatal("marking players inactive", err)
+		}
+
+		printChanges(chs, output, !noColor)
+		save(cs)
+		return
+	}
+
+	if len(args) < 2 {
+		log.Println("missing required command & subcommand")
+		flag.Usage()
+		os.Exit(exitUsage)
 	}
 
+	resource := args[1]
+	action := args[0]
+
 	switch resource {
 	case "club":
+		var rs wa.Recruitment
+		if recruitment != "" {
+			r, err := wa.ParseRecruitment(recruitment)
+			if err != nil {
+				usagef("%v", err)
+			}
+			rs = r
+		}
+
 		switch action {
 		case "get":
-			// todo: update printing method
 			var d any
-			if allClubs {
-				d = cs.All()
-			} else {
-				c, err := cs.Club(clubName)
+			switch {
+			case allClubs && len(ts) > 0:
+				ps, err := cs.TaggedPlayers(ctx, "", ts...)
+				if err != nil {
+					fatal("getting tagged players", err)
+				}
+				d = ps
+			case allClubs:
+				clubs, err := cs.List(ctx)
 				if err != nil {
-					log.Fatalf("getting club: %v", err)
+					fatal("getting clubs", err)
+				}
+				d = clubs
+			default:
+				c, err := cs.Club(ctx, clubName)
+				if err != nil {
+					fatal("getting club", err)
+				}
+				if len(ts) > 0 {
+					c = c.Tagged(ts...)
 				}
 				d = c
 			}
-			wa.Print(d)
+
+			show(d)
 		case "add":
 			c := wa.NewClub(clubName, x, y)
+			c.Capacity, c.Recruitment = capacity, rs
 
-			if err := cs.CreateClub(c); err != nil {
-				log.Fatalf("creating club: %v", err)
+			if err := cs.CreateClub(ctx, c); err != nil {
+				fatal("creating club", err)
 			}
 		case "update":
 			c := wa.NewClub(clubName, x, y)
-			nc, err := cs.UpdateClub(c)
+			c.Capacity, c.Recruitment = capacity, rs
+			nc, err := cs.UpdateClub(ctx, c)
 			if err != nil {
-				log.Fatalf("updating club: %v", err)
+				fatal("updating club", err)
 			}
-			wa.Print(nc)
+			show(nc)
 		case "remove":
-			if err := cs.RemoveClub(clubName); err != nil {
-				log.Fatalf("removing club: %v", err)
+			if err := cs.RemoveClub(ctx, clubName); err != nil {
+				fatal("removing club", err)
 			}
 		default:
-			log.Fatalf("unknown subcommand: %q", action)
+			usagef("unknown subcommand: %q", action)
 		}
 	case "player":
 		p := wa.NewPlayer(name, clubName, level, x, y)
+		if might != "" {
+			m, err := wa.ParseMight(might)
+			if err != nil {
+				usagef("%v", err)
+			}
+			p.Might = m
+		}
 
 		switch action {
 		case "get":
-			gp, err := cs.Player(p.Name)
+			gp, err := cs.Player(ctx, p.Name)
 			if err != nil {
-				log.Fatalf("getting player: %v", err)
+				fatal("getting player", err)
 			}
 
-			print(gp)
+			show(gp)
 		case "add":
-			np, err := cs.CreatePlayer(p.Club, p)
+			np, err := cs.CreatePlayer(ctx, p.Club, p)
 			if err != nil {
-				log.Fatalf("creating player: %v", err)
+				fatal("creating player", err)
 			}
 
-			print(np)
+			show(np)
+			warnCapacity(ctx, cs, p.Club)
 		case "update":
 			if csvLoc != "" {
-				ps, err := wa.ReadCSV(csvLoc)
+				opts, err := csvOptions(columnsLoc, columns, delimiter)
+				if err != nil {
+					fatal("configuring csv import", err)
+				}
+				opts.ContinueOnError = continueOnError
+				if strictClubs {
+					for n := range cs.All() {
+						opts.Clubs = append(opts.Clubs, n)
+					}
+				}
+
+				var res *wa.CSVResult
+				if wa.IsURL(csvLoc) {
+					f := wa.NewCSVFetcher(fetchTimeout)
+					res, err = f.Fetch(ctx, csvLoc, opts)
+				} else {
+					res, err = wa.ReadCSV(csvLoc, opts)
+				}
+				if err != nil {
+					fatal("reading players from csv", err)
+				}
+				reportRejected(res, rejectedLoc)
+
+				ps := res.Players
+
+				mode, err := wa.ParseSyncMode(syncClub)
+				if err != nil {
+					fatal("parsing sync mode", err)
+				}
+				// always show which players will be changed by syncing before anything is applied.
+				if mode != wa.SyncNone {
+					dryRun = true
+				}
+
+				if dryRun {
+					chs, err := cs.PlanBulkUpdate(ctx, ps, mode)
+					if err != nil {
+						fatal("planning bulk update", err)
+					}
+
+					printChanges(chs, output, !noColor)
+
+					if !apply {
+						return
+					}
+				}
+
+				chs, err := cs.BulkUpdatePlayers(ctx, ps, mode)
 				if err != nil {
-					log.Fatalf("reading players from csv: %v", err)
+					fatal("bulk updating players", err)
 				}
 
-				if err := cs.BulkUpdatePlayers(ps); err != nil {
-					log.Fatalf("bulk updating players: %v", err)
+				if !dryRun {
+					printChanges(chs, output, !noColor)
 				}
 				break
 			}
 
-			up, err := cs.UpdatePlayer(p)
+			up, err := cs.UpdatePlayer(ctx, p)
 			if err != nil {
-				log.Fatalf("updating player: %v", err)
+				fatal("updating player", err)
 			}
 
-			print(up)
+			show(up)
 		case "move":
-			mp, err := cs.MovePlayer(p.Name, newClubName)
+			mp, err := cs.MovePlayer(ctx, p.Name, newClubName)
+			if err != nil {
+				fatal("moving player", err)
+			}
+
+			show(mp)
+			warnCapacity(ctx, cs, newClubName)
+		case "promote":
+			chs, err := cs.PromotePlayer(ctx, name)
+			if err != nil {
+				fatal("promoting player", err)
+			}
+
+			printChanges(chs, output, !noColor)
+		case "demote":
+			chs, err := cs.DemotePlayer(ctx, name)
+			if err != nil {
+				fatal("demoting player", err)
+			}
+
+			printChanges(chs, output, !noColor)
+		case "remove":
+			if err := cs.RemovePlayer(ctx, name); err != nil {
+				fatal("removing player", err)
+			}
+		default:
+			usagef("unknown subcommand: %q", action)
+
+		}
+	case "prospect":
+		switch action {
+		case "get":
+			prs, err := cs.Prospects(ctx, clubName)
 			if err != nil {
-				log.Fatalf("moving player: %v", err)
+				fatal("getting prospects", err)
+			}
+
+			show(prs)
+		case "add":
+			pr := &wa.Prospect{Name: name, Level: level, Contact: contact}
+			if might != "" {
+				m, err := wa.ParseMight(might)
+				if err != nil {
+					usagef("%v", err)
+				}
+				pr.Might = m
 			}
 
-			print(mp)
+			if err := cs.AddProspect(ctx, clubName, pr); err != nil {
+				fatal("adding prospect", err)
+			}
 		case "remove":
-			if err := cs.RemovePlayer(name); err != nil {
-				log.Fatalf("removing player: %v", err)
+			if err := cs.RemoveProspect(ctx, clubName, name); err != nil {
+				fatal("removing prospect", err)
+			}
+		case "promote":
+			p, err := cs.PromoteProspect(ctx, clubName, name)
+			if err != nil {
+				fatal("promoting prospect", err)
+			}
+
+			show(p)
+			warnCapacity(ctx, cs, clubName)
+		default:
+			usagef("unknown subcommand: %q", action)
+		}
+	case "tag":
+		if len(ts) == 0 {
+			usagef("--tag is required")
+		}
+
+		switch action {
+		case "get":
+			ps, err := cs.TaggedPlayers(ctx, clubName, ts...)
+			if err != nil {
+				fatal("getting tagged players", err)
+			}
+
+			show(ps)
+		case "add", "remove":
+			// players are tagged when named, otherwise their club is.
+			var d any
+			var err error
+			switch {
+			case name != "" && action == "add":
+				d, err = cs.TagPlayer(ctx, name, ts...)
+			case name != "":
+				d, err = cs.UntagPlayer(ctx, name, ts...)
+			case action == "add":
+				d, err = cs.TagClub(ctx, clubName, ts...)
+			default:
+				d, err = cs.UntagClub(ctx, clubName, ts...)
 			}
+			if err != nil {
+				fatal("tagging", err)
+			}
+
+			show(d)
 		default:
-			log.Fatalf("unknown subcommand: %q", action)
+			usagef("unknown subcommand: %q", action)
+		}
+	case "role":
+		if action != "update" {
+			usagef("unknown subcommand: %q", action)
+		}
+
+		r, err := wa.ParseRole(role)
+		if err != nil {
+			usagef("%v", err)
+		}
+
+		chs, err := cs.SetRole(ctx, name, r)
+		if err != nil {
+			fatal("updating role", err)
+		}
 
+		printChanges(chs, output, !noColor)
+	case "notes":
+		if action != "update" {
+			usagef("unknown subcommand: %q", action)
+		}
+
+		var d any
+		if name != "" {
+			d, err = cs.SetPlayerNotes(ctx, name, notes)
+		} else {
+			d, err = cs.SetClubNotes(ctx, clubName, notes)
+		}
+		if err != nil {
+			fatal("updating notes", err)
 		}
+
+		show(d)
 	default:
-		log.Fatalf("unknown resource: %q", resource)
+		usagef("unknown resource: %q", resource)
+	}
+
+	if writes(args) {
+		save(cs)
+	}
+}
+
+// warnCapacity logs a warning when the named club has more players than its capacity.
+func warnCapacity(ctx context.Context, cs *wa.Clubs, clubName string) {
+	c, err := cs.Club(ctx, clubName)
+	if err != nil {
+		return
+	}
+
+	if w := c.CapacityWarning(); w != "" {
+		log.Printf("warning: %s", w)
+	}
+}
+
+// configure loads settings from the config file and environment, overridden by any flags given.
+func configure(loc string) *wa.Config {
+	cfg, err := wa.LoadConfig(loc, wa.DefaultConfig())
+	if err != nil {
+		fatal("loading config", err)
 	}
 
-	for _, a := range []string{"add", "update", "remove", "move"} {
-		if action != a {
+	for _, k := range wa.ConfigKeys() {
+		f := flag.Lookup(k)
+		if f == nil || !f.Changed {
 			continue
 		}
+		if err := cfg.Set(k, f.Value.String()); err != nil {
+			fatal("reading flags", err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fatal("invalid config", err)
+	}
+
+	return cfg
+}
+
+// writes returns true if the command given by args changes the data file.
+func writes(args []string) bool {
+	if len(args) > 1 && args[0] == "backup" {
+		return args[1] == "restore"
+	}
+	if len(args) > 1 && args[0] == "event" {
+		return args[1] == "create" || args[1] == "attend"
+	}
 
-		save(dataLoc, cs)
+	for _, a := range writeActions {
+		if args[0] == a {
+			return true
+		}
 	}
+
+	return false
 }
 
 func print(d any) {
 	if err := wa.Print(d); err != nil {
-		log.Fatalf("printing: %v", err)
+		fatal("printing", err)
+	}
+}
+
+func printChanges(chs *wa.ChangeSet, format string, color bool) {
+	switch format {
+	case "json":
+		print(chs)
+	case "text", "diff":
+		if err := chs.WriteDiff(os.Stdout, color); err != nil {
+			fatal("printing changes", err)
+		}
+	default:
+		usagef("unknown output format: %q", format)
 	}
 }
 
-func save(loc string, cs *wa.Clubs) {
-	if err := wa.Save(loc, cs.All()); err != nil {
-		log.Fatalf("saving data: %v", err)
+func csvOptions(columnsLoc string, columns map[string]string, delimiter string) (*wa.CSVOptions, error) {
+	opts := &wa.CSVOptions{Columns: map[string]string{}}
+
+	if columnsLoc != "" {
+		m, err := wa.ReadColumnMapping(columnsLoc)
+		if err != nil {
+			return nil, fmt.Errorf("reading column mapping: %w", err)
+		}
+		opts.Columns = m
+	}
+	// columns given as flags take precedence over the mapping file.
+	for h, f := range columns {
+		opts.Columns[h] = f
+	}
+
+	switch delimiter {
+	case "":
+	case "comma", ",":
+		opts.Delimiter = ','
+	case "tab", "\\t":
+		opts.Delimiter = '\t'
+	case "semicolon", ";":
+		opts.Delimiter = ';'
+	default:
+		return nil, fmt.Errorf("unknown delimiter %q", delimiter)
+	}
+
+	return opts, nil
+}
+
+func reportRejected(res *wa.CSVResult, loc string) {
+	if len(res.Rejected) == 0 {
+		return
+	}
+
+	for _, re := range res.Rejected {
+		log.Printf("skipping row: %v", re)
+	}
+	log.Printf("%d rows rejected", len(res.Rejected))
+
+	if loc == "" {
+		return
+	}
+
+	f, err := os.Create(loc)
+	if err != nil {
+		fatal("creating rejected rows file", err)
+	}
+	defer f.Close()
+
+	if err := res.WriteRejected(f); err != nil {
+		fatal("writing rejected rows", err)
+	}
+}
+
+func save(cs *wa.Clubs) {
+	err := cs.Save()
+	if errors.Is(err, wa.ErrDataChanged) {
+		log.Printf("not saving: %v. another change was saved while this one ran, run it again to apply it on top", err)
+		os.Exit(exitConflict)
+	}
+	if err != nil {
+		fatal("saving data", err)
 	}
 }
@@ -0,0 +1,203 @@
+package witcharcana
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ConfigFile is the name of the config file looked for in the working and user config dirs.
+	ConfigFile = "witcharcana.yaml"
+	// EnvPrefix prefixes the environment variables settings are read from, ie: WITCHARCANA_DATA.
+	EnvPrefix = "WITCHARCANA_"
+)
+
+// Backend names where club data is stored.
+type Backend string
+
+const (
+	// BackendFile stores club data in a json data file.
+	BackendFile Backend = "file"
+	// BackendMongo stores club data in mongodb with a collection per discord server.
+	BackendMongo Backend = "mongo"
+)
+
+// Config holds the settings shared by the cli and bot. Settings are read from a yaml config file,
+// then WITCHARCANA_* environment variables and finally command line flags, with later sources taking
+// precedence.
+type Config struct {
+	Backend     Backend  `yaml:"backend"`
+	Data        string   `yaml:"data"`
+	TemplateDir string   `yaml:"template_dir,omitempty"`
+	Token       string   `yaml:"token,omitempty"`
+	DB          DBConfig `yaml:"db,omitempty"`
+	Verbose     bool     `yaml:"verbose,omitempty"`
+}
+
+// DefaultConfig returns the settings used when nothing else is configured.
+func DefaultConfig() *Config {
+	return &Config{
+		Backend: BackendFile,
+		Data:    "clubs.json",
+	}
+}
+
+// configKeys maps each setting's key, as used by Set and in environment variable names, onto the
+// field it sets.
+var configKeys = map[string]func(c *Config) any{
+	"backend":      func(c *Config) any { return &c.Backend },
+	"data":         func(c *Config) any { return &c.Data },
+	"template-dir": func(c *Config) any { return &c.TemplateDir },
+	"token":        func(c *Config) any { return &c.Token },
+	"db-conn":      func(c *Config) any { return &c.DB.Loc },
+	"db-name":      func(c *Config) any { return &c.DB.Name },
+	"db-user":      func(c *Config) any { return &c.DB.User },
+	"db-pass":      func(c *Config) any { return &c.DB.Pass },
+	"verbose":      func(c *Config) any { return &c.Verbose },
+}
+
+// ConfigKeys returns the keys of every setting, sorted.
+func ConfigKeys() []string {
+	ks := make([]string, 0, len(configKeys))
+	for k := range configKeys {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+
+	return ks
+}
+
+// EnvVar returns the name of the environment variable for the setting key, ie: db-conn is read from
+// WITCHARCANA_DB_CONN.
+func EnvVar(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// Set sets the setting key to the string value given, as read from an environment variable or flag.
+func (c *Config) Set(key, value string) error {
+	field, ok := configKeys[key]
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+
+	switch f := field(c).(type) {
+	case *string:
+		*f = value
+	case *Backend:
+		*f = Backend(value)
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("setting %s: invalid value %q", key, value)
+		}
+		*f = b
+	}
+
+	return nil
+}
+
+// LoadConfig reads settings from the config file at loc over the defaults given, followed by any set
+// environment variables. When loc is empty, WITCHARCANA_CONFIG is used and otherwise the first
+// witcharcana.yaml found in the working directory or user config dir, if any.
+func LoadConfig(loc string, defaults *Config) (*Config, error) {
+	c := *defaults
+
+	if loc == "" {
+		loc = os.Getenv(EnvVar("config"))
+	}
+	if loc == "" {
+		loc = findConfig()
+	}
+
+	if loc != "" {
+		dat, err := os.ReadFile(loc)
+		if err != nil {
+			return nil, fmt.Errorf("reading config: %w", err)
+		}
+		if err := yaml.Unmarshal(dat, &c); err != nil {
+			return nil, fmt.Errorf("decoding config %q: %w", loc, err)
+		}
+	}
+
+	for _, k := range ConfigKeys() {
+		v, ok := os.LookupEnv(EnvVar(k))
+		if !ok {
+			continue
+		}
+		if err := c.Set(k, v); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", EnvVar(k), err)
+		}
+	}
+
+	return &c, nil
+}
+
+// Validate checks the settings needed by the chosen backend are present.
+func (c *Config) Validate() error {
+	switch c.Backend {
+	case BackendFile:
+		if c.Data == "" {
+			return fmt.Errorf("data file location required for the %s backend", c.Backend)
+		}
+	case BackendMongo:
+		if c.DB.Loc == "" || c.DB.Name == "" {
+			return fmt.Errorf("db connection and name required for the %s backend", c.Backend)
+		}
+	default:
+		return fmt.Errorf("unknown backend %q. options: [%s %s]", c.Backend, BackendFile, BackendMongo)
+	}
+
+	return nil
+}
+
+// WriteConfig writes the settings to a new config file at loc, leaving out secrets which are better
+// kept in environment variables. ErrAlreadyExists is returned when there's already a file at loc.
+func WriteConfig(loc string, c *Config) error {
+	wc := *c
+	wc.Token = ""
+	wc.DB.Pass = ""
+
+	dat, err := yaml.Marshal(&wc)
+	if err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+
+	f, err := os.OpenFile(loc, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("config file %q %w", loc, ErrAlreadyExists)
+	}
+	if err != nil {
+		return fmt.Errorf("creating config: %w", err)
+	}
+	if _, err := f.Write(dat); err != nil {
+		f.Close()
+		return fmt.Errorf("writing config: %w", err)
+	}
+
+	return f.Close()
+}
+
+// findConfig returns the location of the first config file found in the working directory or user
+// config dir, or an empty string when there isn't one.
+func findConfig() string {
+	locs := []string{ConfigFile}
+	if dir, err := os.UserConfigDir(); err == nil {
+		locs = append(locs, filepath.Join(dir, "witcharcana", ConfigFile))
+	}
+
+	for _, l := range locs {
+		if _, err := os.Stat(l); err == nil {
+			return l
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,121 @@
+package witcharcana
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	loc := filepath.Join(dir, "witcharcana.yaml")
+	assert.NoError(t, os.WriteFile(loc, []byte("backend: mongo\ndata: file.json\ndb:\n  conn: mongodb://file\n  name: wa\n"), 0600))
+
+	testCases := []struct {
+		name        string
+		loc         string
+		env         map[string]string
+		expected    *Config
+		expectedErr error
+	}{
+		{
+			name:     "defaults",
+			loc:      "",
+			expected: &Config{Backend: BackendFile, Data: "clubs.json"},
+		},
+		{
+			name: "file",
+			loc:  loc,
+			expected: &Config{Backend: BackendMongo, Data: "file.json", DB: DBConfig{
+				Loc: "mongodb://file", Name: "wa",
+			}},
+		},
+		{
+			name: "env over file",
+			loc:  loc,
+			env: map[string]string{
+				"WITCHARCANA_DATA":    "env.json",
+				"WITCHARCANA_DB_PASS": "secret",
+				"WITCHARCANA_VERBOSE": "true",
+			},
+			expected: &Config{Backend: BackendMongo, Data: "env.json", Verbose: true, DB: DBConfig{
+				Loc: "mongodb://file", Name: "wa", Pass: "secret",
+			}},
+		},
+		{
+			name:     "file from env",
+			env:      map[string]string{"WITCHARCANA_CONFIG": loc},
+			expected: &Config{Backend: BackendMongo, Data: "file.json", DB: DBConfig{Loc: "mongodb://file", Name: "wa"}},
+		},
+		{
+			name:        "invalid env",
+			env:         map[string]string{"WITCHARCANA_VERBOSE": "sometimes"},
+			expectedErr: fmt.Errorf(`reading WITCHARCANA_VERBOSE: setting verbose: invalid value "sometimes"`),
+		},
+		{
+			name:        "missing file",
+			loc:         filepath.Join(dir, "nope.yaml"),
+			expectedErr: fmt.Errorf("reading config: open %s: no such file or directory", filepath.Join(dir, "nope.yaml")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// keep config files in the working dir from being found.
+			wd, err := os.Getwd()
+			assert.NoError(t, err)
+			assert.NoError(t, os.Chdir(t.TempDir()))
+			t.Cleanup(func() { os.Chdir(wd) })
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			for _, k := range append(ConfigKeys(), "config") {
+				t.Setenv(EnvVar(k), "")
+				os.Unsetenv(EnvVar(k))
+			}
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			actual, err := LoadConfig(tc.loc, DefaultConfig())
+
+			assert.Equal(t, tc.expected, actual)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfigSetAndValidate(t *testing.T) {
+	c := DefaultConfig()
+	assert.NoError(t, c.Validate())
+
+	assert.NoError(t, c.Set("backend", "mongo"))
+	assert.EqualError(t, c.Validate(), "db connection and name required for the mongo backend")
+
+	assert.NoError(t, c.Set("db-conn", "mongodb://localhost"))
+	assert.NoError(t, c.Set("db-name", "wa"))
+	assert.NoError(t, c.Validate())
+
+	assert.EqualError(t, c.Set("colour", "red"), `unknown setting "colour"`)
+	assert.NoError(t, c.Set("backend", "sqlite"))
+	assert.EqualError(t, c.Validate(), `unknown backend "sqlite". options: [file mongo]`)
+}
+
+func TestWriteConfig(t *testing.T) {
+	loc := filepath.Join(t.TempDir(), "witcharcana.yaml")
+	c := &Config{Backend: BackendFile, Data: "clubs.json", Token: "secret", DB: DBConfig{User: "wa", Pass: "secret"}}
+
+	assert.NoError(t, WriteConfig(loc, c))
+
+	actual, err := LoadConfig(loc, &Config{})
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{Backend: BackendFile, Data: "clubs.json", DB: DBConfig{User: "wa"}}, actual)
+
+	assert.True(t, errors.Is(WriteConfig(loc, c), ErrAlreadyExists))
+}
@@ -0,0 +1,308 @@
+package witcharcana
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVOptions configures how player rows are read from a csv.
+type CSVOptions struct {
+	// ContinueOnError collects invalid rows instead of stopping at the first one.
+	ContinueOnError bool
+	// Clubs, when set, restricts rows to the listed club names. Rows for any other club are rejected.
+	Clubs []string
+	// Columns maps csv headers onto player fields, ie: "IGN": "name". Headers are matched without
+	// regard to case. See CSVFields for the fields available.
+	Columns map[string]string
+	// Delimiter separates the fields of each row. It is detected from the header when unset.
+	Delimiter rune
+}
+
+// CSVFields are the player fields csv columns can be mapped onto. Locations can be given either as
+// a single "x:y" location column or as separate x and y columns. Players aren't marked inactive by
+// imports, see SyncMode for handling players missing from them.
+var CSVFields = []string{"name", "club", "level", "might", "location", "x", "y", "in_hive", "tags"}
+
+// csvAliases maps headers commonly used by spreadsheets onto the player fields they hold. They're
+// matched without regard to case and can be overridden by CSVOptions.Columns.
+var csvAliases = map[string]string{
+	"ign":    "name",
+	"guild":  "club",
+	"lvl":    "level",
+	"coords": "location",
+}
+
+// csvDelimiters are the delimiters checked for when detecting how a csv is separated.
+var csvDelimiters = []rune{',', '\t', ';'}
+
+// CSVResult holds the players read from a csv along with any rows that were rejected.
+type CSVResult struct {
+	Header   []string    `json:"header"`
+	Players  Players     `json:"players"`
+	Rejected []*RowError `json:"rejected,omitempty"`
+}
+
+// RowError describes a csv row that could not be imported.
+type RowError struct {
+	Line int      `json:"line"`
+	Row  []string `json:"row"`
+	Err  error    `json:"-"`
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.reason())
+}
+
+// reason returns the row's errors on a single line.
+func (e *RowError) reason() string {
+	return strings.ReplaceAll(e.Err.Error(), "\n", "; ")
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeCSV streams player rows from r, validating each as it's read.
+func DecodeCSV(r io.Reader, opts *CSVOptions) (*CSVResult, error) {
+	if opts == nil {
+		opts = &CSVOptions{}
+	}
+
+	br := bufio.NewReader(r)
+	delim := opts.Delimiter
+	if delim == 0 {
+		first, err := br.Peek(br.Size())
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, fmt.Errorf("reading header: %w", err)
+		}
+
+		delim = detectDelimiter(string(first))
+	}
+
+	cr := csv.NewReader(br)
+	cr.Comma = delim
+	cr.FieldsPerRecord = -1
+	// trimming would otherwise swallow empty tab separated fields.
+	cr.TrimLeadingSpace = delim != '\t'
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	cols, err := columns(header, opts.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("mapping columns: %w", err)
+	}
+	if _, ok := cols["name"]; !ok {
+		return nil, fmt.Errorf("header %v is missing a name column", header)
+	}
+
+	var known map[string]bool
+	if len(opts.Clubs) > 0 {
+		known = map[string]bool{}
+		for _, c := range opts.Clubs {
+			known[c] = true
+		}
+	}
+
+	res := &CSVResult{Header: header, Players: Players{}}
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var p *Player
+		var line int
+		var pe *csv.ParseError
+		switch {
+		case errors.As(err, &pe):
+			line = pe.Line
+		case err != nil:
+			return nil, fmt.Errorf("reading row: %w", err)
+		default:
+			line, _ = cr.FieldPos(0)
+			p, err = decodeRow(cols, header, row, known)
+		}
+		if err != nil {
+			re := &RowError{Line: line, Row: row, Err: err}
+			if !opts.ContinueOnError {
+				return nil, re
+			}
+
+			res.Rejected = append(res.Rejected, re)
+			continue
+		}
+
+		res.Players = append(res.Players, p)
+	}
+
+	return res, nil
+}
+
+// WriteRejected writes the rejected rows to w as a csv using the original header plus an error
+// column, allowing them to be fixed and imported again.
+func (res *CSVResult) WriteRejected(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(append(append([]string{}, res.Header...), "error")); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+	for _, re := range res.Rejected {
+		row := append(append([]string{}, re.Row...), re.reason())
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("writing line %d: %w", re.Line, err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("flushing: %w", err)
+	}
+
+	return nil
+}
+
+// detectDelimiter returns the most common of the supported delimiters found in the first line of
+// data, defaulting to a comma.
+func detectDelimiter(data string) rune {
+	line, _, _ := strings.Cut(data, "\n")
+
+	d, most := ',', 0
+	for _, c := range csvDelimiters {
+		if n := strings.Count(line, string(c)); n > most {
+			d, most = c, n
+		}
+	}
+
+	return d
+}
+
+// columns maps player field names to their index in header, using mapping and then the common
+// aliases to translate headers that don't match a field name.
+func columns(header []string, mapping map[string]string) (map[string]int, error) {
+	fields := make(map[string]bool, len(CSVFields))
+	for _, f := range CSVFields {
+		fields[f] = true
+	}
+
+	m := make(map[string]string, len(csvAliases)+len(mapping))
+	for h, f := range csvAliases {
+		m[h] = f
+	}
+	for h, f := range mapping {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if !fields[f] {
+			return nil, fmt.Errorf("column %q mapped to unknown field %q. options: %v", h, f, CSVFields)
+		}
+		m[strings.ToLower(strings.TrimSpace(h))] = f
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if f, ok := m[h]; ok {
+			h = f
+		}
+		if _, ok := cols[h]; ok && fields[h] {
+			return nil, fmt.Errorf("multiple columns found for field %q", h)
+		}
+		cols[h] = i
+	}
+
+	return cols, nil
+}
+
+func decodeRow(cols map[string]int, header, row []string, known map[string]bool) (*Player, error) {
+	if len(row) != len(header) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(header), len(row))
+	}
+
+	cell := func(name string) string {
+		if i, ok := cols[name]; ok {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var errs []error
+
+	p := &Player{
+		Name: cell("name"),
+		Club: cell("club"),
+	}
+	if p.Club == "" {
+		errs = append(errs, fmt.Errorf("club required"))
+	} else if known != nil && !known[p.Club] {
+		errs = append(errs, fmt.Errorf("unknown club %q", p.Club))
+	}
+
+	if v := cell("level"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid level %q", v))
+		} else {
+			p.Level = l
+		}
+	}
+
+	if v := cell("might"); v != "" {
+		m, err := ParseMight(v)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			p.Might = m
+		}
+	}
+
+	if v := cell("location"); v != "" {
+		l := &Location{}
+		if err := l.UnmarshalCSV(v); err != nil {
+			errs = append(errs, err)
+		} else {
+			p.Location = l
+		}
+	} else if xv, yv := cell("x"), cell("y"); xv != "" || yv != "" {
+		l := &Location{}
+		if err := l.UnmarshalCSV(xv + ":" + yv); err != nil {
+			errs = append(errs, err)
+		} else {
+			p.Location = l
+		}
+	}
+
+	if v := cell("in_hive"); v != "" {
+		h, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid in_hive value %q", v))
+		} else {
+			p.InHive = h
+		}
+	}
+
+	// a tags cell replaces every tag of the player, so empty cells are left alone.
+	if v := cell("tags"); v != "" {
+		ts, err := ParseTags(v)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			p.Tags = ts
+		}
+	}
+
+	for _, fe := range p.validate("") {
+		errs = append(errs, fe)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,191 @@
+package witcharcana
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCSV(t *testing.T) {
+	testCases := []struct {
+		name        string
+		data        string
+		opts        *CSVOptions
+		expected    *CSVResult
+		expectedErr error
+	}{
+		{
+			name:        "missing name column",
+			data:        "club,level\nCNT,18\n",
+			expectedErr: fmt.Errorf("header [club level] is missing a name column"),
+		},
+		{
+			name: "valid rows",
+			data: "name,club,lvl,might,location,in_hive\n" +
+				"mxygem,CNT,18,51848883,123:456,true\n" +
+				"Hoeb,CNT,,,,\n" +
+				"Quinoa,SP,16,70.2M,,\n",
+			expected: &CSVResult{
+				Header: []string{"name", "club", "lvl", "might", "location", "in_hive"},
+				Players: Players{
+					{Name: "mxygem", Club: "CNT", Level: 18, Might: 51848883, Location: &Location{X: 123, Y: 456}, InHive: true},
+					{Name: "Hoeb", Club: "CNT"},
+					{Name: "Quinoa", Club: "SP", Level: 16, Might: 70200000},
+				},
+			},
+		},
+		{
+			name: "stops at first invalid row",
+			data: "name,club,location\n" +
+				"mxygem,CNT,123:456\n" +
+				"Hoeb,CNT,123-456\n" +
+				"M4rs,,1:2\n",
+			expectedErr: fmt.Errorf(`line 3: invalid location of "123-456" received`),
+		},
+		{
+			name: "continues on invalid rows",
+			data: "name,club,level,location\n" +
+				"mxygem,CNT,18,123:456\n" +
+				"Hoeb,CNT,-2,123-456\n" +
+				"M4rs,,15\n" +
+				"Quinoa,SP,16,1:2\n",
+			opts: &CSVOptions{ContinueOnError: true},
+			expected: &CSVResult{
+				Header: []string{"name", "club", "level", "location"},
+				Players: Players{
+					{Name: "mxygem", Club: "CNT", Level: 18, Location: &Location{X: 123, Y: 456}},
+					{Name: "Quinoa", Club: "SP", Level: 16, Location: &Location{X: 1, Y: 2}},
+				},
+			},
+		},
+		{
+			name: "unknown clubs rejected",
+			data: "name,club\n" +
+				"mxygem,CNT\n" +
+				"Quinoa,SP\n",
+			opts:        &CSVOptions{Clubs: []string{"CNT"}},
+			expectedErr: fmt.Errorf(`line 3: unknown club "SP"`),
+		},
+		{
+			name: "mapped columns with separate x and y",
+			data: "IGN,Guild,Lvl,X,Y\n" +
+				"mxygem,CNT,18,123,456\n" +
+				"Hoeb,CNT,15,,\n",
+			opts: &CSVOptions{Columns: map[string]string{"ign": "name", "Guild": "club", "x": "x", "Y": "y"}},
+			expected: &CSVResult{
+				Header: []string{"IGN", "Guild", "Lvl", "X", "Y"},
+				Players: Players{
+					{Name: "mxygem", Club: "CNT", Level: 18, Location: &Location{X: 123, Y: 456}},
+					{Name: "Hoeb", Club: "CNT", Level: 15},
+				},
+			},
+		},
+		{
+			name: "common headers detected",
+			data: "IGN;guild;LVL;Coords;Might\n" +
+				"mxygem;CNT;18;123:456;70.2m\n",
+			expected: &CSVResult{
+				Header: []string{"IGN", "guild", "LVL", "Coords", "Might"},
+				Players: Players{
+					{Name: "mxygem", Club: "CNT", Level: 18, Might: 70200000, Location: &Location{X: 123, Y: 456}},
+				},
+			},
+		},
+		{
+			name:        "column mapped to unknown field",
+			data:        "IGN,Guild\nmxygem,CNT\n",
+			opts:        &CSVOptions{Columns: map[string]string{"Guild": "clan"}},
+			expectedErr: fmt.Errorf(`mapping columns: column "Guild" mapped to unknown field "clan". options: [name club level might location x y in_hive tags]`),
+		},
+		{
+			name:        "missing y column value",
+			data:        "name,club,x,y\nmxygem,CNT,123,\n",
+			expectedErr: fmt.Errorf(`line 2: invalid integer "" received for location Y value`),
+		},
+		{
+			name: "tab delimited",
+			data: "name\tclub\tlevel\tlocation\n" +
+				"mxygem\tCNT\t\t123:456\n",
+			expected: &CSVResult{
+				Header: []string{"name", "club", "level", "location"},
+				Players: Players{
+					{Name: "mxygem", Club: "CNT", Location: &Location{X: 123, Y: 456}},
+				},
+			},
+		},
+		{
+			name: "semicolon delimited",
+			data: "name;club;level\n" +
+				"mxygem;CNT;18\n",
+			expected: &CSVResult{
+				Header: []string{"name", "club", "level"},
+				Players: Players{
+					{Name: "mxygem", Club: "CNT", Level: 18},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := DecodeCSV(strings.NewReader(tc.data), tc.opts)
+
+			if tc.expected != nil && actual != nil {
+				assert.Equal(t, tc.expected.Header, actual.Header)
+				assert.Equal(t, tc.expected.Players, actual.Players)
+			} else {
+				assert.Equal(t, tc.expected, actual)
+			}
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCSVResultWriteRejected(t *testing.T) {
+	data := "name,club,level,location\n" +
+		"mxygem,CNT,18,123:456\n" +
+		"Hoeb,CNT,-2,123-456\n" +
+		"M4rs,,15\n"
+
+	res, err := DecodeCSV(strings.NewReader(data), &CSVOptions{ContinueOnError: true})
+	assert.NoError(t, err)
+
+	if assert.Len(t, res.Rejected, 2) {
+		assert.Equal(t, 3, res.Rejected[0].Line)
+		assert.Equal(t, 4, res.Rejected[1].Line)
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, res.WriteRejected(&b))
+
+	expected := "name,club,level,location,error\n" +
+		`Hoeb,CNT,-2,123-456,"invalid location of ""123-456"" received; level must be between 0 and 100. got -2"` + "\n" +
+		"M4rs,,15,\"expected 4 fields, got 3\"\n"
+	assert.Equal(t, expected, b.String())
+}
+
+func TestDetectDelimiter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected rune
+	}{
+		{name: "empty", data: "", expected: ','},
+		{name: "comma", data: "name,club,level\nmxygem;CNT;18", expected: ','},
+		{name: "tab", data: "name\tclub\tlevel,lvl", expected: '\t'},
+		{name: "semicolon", data: "name;club;level", expected: ';'},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, detectDelimiter(tc.data))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package witcharcana
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DataVersion is the version of the data file format written by Save. Files from older versions are
+// migrated when loaded while files from newer versions are refused.
+const DataVersion = 1
+
+// dataFile is the top level layout of the data file.
+type dataFile struct {
+	Version   int              `json:"version"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Clubs     map[string]*Club `json:"clubs"`
+	Events    Events           `json:"events,omitempty"`
+	Schedules Schedules        `json:"schedules,omitempty"`
+}
+
+// migration upgrades raw file data from one version to the next.
+type migration func(dat []byte) ([]byte, error)
+
+// migrations holds the migration from each version, by index, to the one after it.
+var migrations = []migration{
+	0: migrateV0,
+}
+
+// decodeDataFile decodes raw file data of any supported version, migrating it to DataVersion.
+func decodeDataFile(dat []byte) (*dataFile, error) {
+	v, err := dataVersion(dat)
+	if err != nil {
+		return nil, err
+	}
+	if v < 0 {
+		return nil, fmt.Errorf("data file version %d is invalid", v)
+	}
+	if v > DataVersion {
+		return nil, fmt.Errorf("data file version %d is newer than the supported version %d", v, DataVersion)
+	}
+
+	for ; v < DataVersion; v++ {
+		if dat, err = migrations[v](dat); err != nil {
+			return nil, fmt.Errorf("migrating from version %d: %w", v, err)
+		}
+	}
+
+	df := &dataFile{}
+	if err := json.Unmarshal(dat, df); err != nil {
+		return nil, err
+	}
+
+	return df, nil
+}
+
+// dataVersion returns the version of raw file data. Files without a numeric version field predate
+// versioning and are version 0.
+func dataVersion(dat []byte) (int, error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(dat, &top); err != nil {
+		return 0, err
+	}
+
+	// a version 0 file is a bare map of clubs, so a club could be named "version".
+	var v int
+	if err := json.Unmarshal(top["version"], &v); err != nil {
+		return 0, nil
+	}
+
+	return v, nil
+}
+
+// migrateV0 wraps the original bare map of club names to clubs in the versioned layout.
+func migrateV0(dat []byte) ([]byte, error) {
+	var clubs map[string]*Club
+	if err := json.Unmarshal(dat, &clubs); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&dataFile{Version: 1, Clubs: clubs})
+}
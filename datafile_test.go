@@ -0,0 +1,165 @@
+package witcharcana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeDataFile(t *testing.T) {
+	testCases := []struct {
+		name        string
+		data        string
+		expected    *dataFile
+		expectedErr error
+	}{
+		{
+			name: "unversioned bare map",
+			data: `{"CNT":{"name":"CNT","players":[{"name":"mxygem"}]}}`,
+			expected: &dataFile{
+				Version: DataVersion,
+				Clubs: map[string]*Club{
+					"CNT": {Name: "CNT", Players: Players{{Name: "mxygem"}}},
+				},
+			},
+		},
+		{
+			name: "unversioned club named version",
+			data: `{"version":{"name":"version"}}`,
+			expected: &dataFile{
+				Version: DataVersion,
+				Clubs: map[string]*Club{
+					"version": {Name: "version"},
+				},
+			},
+		},
+		{
+			name: "current version",
+			data: `{"version":1,"updated_at":"2023-06-20T18:30:00Z","clubs":{"CNT":{"name":"CNT"}}}`,
+			expected: &dataFile{
+				Version:   DataVersion,
+				UpdatedAt: time.Date(2023, 6, 20, 18, 30, 0, 0, time.UTC),
+				Clubs: map[string]*Club{
+					"CNT": {Name: "CNT"},
+				},
+			},
+		},
+		{
+			name:        "newer version",
+			data:        `{"version":2,"clubs":{}}`,
+			expectedErr: fmt.Errorf("data file version 2 is newer than the supported version 1"),
+		},
+		{
+			name:        "negative version",
+			data:        `{"version":-1,"clubs":{}}`,
+			expectedErr: fmt.Errorf("data file version -1 is invalid"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := decodeDataFile([]byte(tc.data))
+
+			assert.Equal(t, tc.expected, actual)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSaveVersioned(t *testing.T) {
+	loc := filepath.Join(t.TempDir(), "clubs.json")
+	clubs := map[string]*Club{
+		"CNT": {Name: "CNT", Location: &Location{X: 123, Y: 456}, Players: Players{{Name: "mxygem"}}},
+	}
+
+	before := time.Now().UTC()
+	assert.NoError(t, Save(loc, clubs))
+
+	cs, err := loadData(loc)
+	assert.NoError(t, err)
+	assert.Equal(t, clubs, cs.clubs)
+	assert.False(t, cs.updatedAt.Before(before.Truncate(time.Second)))
+}
+
+func TestCsSaveDataChanged(t *testing.T) {
+	loc := filepath.Join(t.TempDir(), "clubs.json")
+	assert.NoError(t, Save(loc, map[string]*Club{"CNT": {Name: "CNT"}}))
+
+	first := NewClubs(nil, false)
+	assert.NoError(t, first.LoadData(loc))
+	second := NewClubs(nil, false)
+	assert.NoError(t, second.LoadData(loc))
+
+	assert.NoError(t, first.CreateClub(context.Background(), &Club{Name: "SP"}))
+	assert.NoError(t, first.Save())
+
+	// saving again after its own save is fine
+	assert.NoError(t, first.CreateClub(context.Background(), &Club{Name: "MID"}))
+	assert.NoError(t, first.Save())
+
+	assert.NoError(t, second.CreateClub(context.Background(), &Club{Name: "KMA"}))
+	err := second.Save()
+	assert.True(t, errors.Is(err, ErrDataChanged))
+
+	cs, err := loadData(loc)
+	assert.NoError(t, err)
+	assert.Len(t, cs.clubs, 3)
+	assert.Nil(t, cs.clubs["KMA"])
+}
+
+func TestCsReload(t *testing.T) {
+	loc := filepath.Join(t.TempDir(), "clubs.json")
+	assert.NoError(t, Save(loc, map[string]*Club{"CNT": {Name: "CNT"}}))
+
+	first := NewClubs(nil, false)
+	assert.NoError(t, first.LoadData(loc))
+	second := NewClubs(nil, false)
+	assert.NoError(t, second.LoadData(loc))
+
+	assert.NoError(t, first.CreateClub(context.Background(), &Club{Name: "SP"}))
+	assert.NoError(t, first.Save())
+
+	// unsaved changes are dropped in favour of the saved ones
+	assert.NoError(t, second.CreateClub(context.Background(), &Club{Name: "KMA"}))
+	assert.NoError(t, second.Reload())
+	assert.Len(t, second.All(), 2)
+	assert.NotNil(t, second.All()["SP"])
+
+	assert.NoError(t, second.CreateClub(context.Background(), &Club{Name: "KMA"}))
+	assert.NoError(t, second.Save())
+}
+
+func TestInitDataFile(t *testing.T) {
+	loc := filepath.Join(t.TempDir(), "data", "clubs.json")
+
+	assert.NoError(t, InitDataFile(loc))
+
+	cs := NewClubs(nil, false)
+	assert.NoError(t, cs.LoadData(loc))
+	assert.Empty(t, cs.All())
+	assert.False(t, cs.UpdatedAt().IsZero())
+
+	bs, err := Backups(loc)
+	assert.NoError(t, err)
+	assert.Empty(t, bs)
+
+	// existing files are left alone
+	assert.NoError(t, cs.CreateClub(context.Background(), &Club{Name: "AZA"}))
+	assert.NoError(t, cs.Save())
+
+	err = InitDataFile(loc)
+	assert.True(t, errors.Is(err, ErrAlreadyExists))
+
+	cs, err = loadData(loc)
+	assert.NoError(t, err)
+	assert.Len(t, cs.clubs, 1)
+}
@@ -2,6 +2,7 @@ package witcharcana
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,32 +12,32 @@ import (
 )
 
 // DB is a representation of the database in use and provides methods for interaction with stored
-// data.
+// data. Each method takes the context of the request it's made for so a cancelled request stops
+// waiting on the database.
 type DB struct {
-	ctx  context.Context
 	cfg  *DBConfig
 	conn *mongo.Client
 	coll *mongo.Collection
 }
 
+// DBConfig holds the connection settings for the mongo backend.
 type DBConfig struct {
-	Loc  string
-	Name string
-	User string
-	Pass string
+	Loc  string `yaml:"conn,omitempty"`
+	Name string `yaml:"name,omitempty"`
+	User string `yaml:"user,omitempty"`
+	Pass string `yaml:"pass,omitempty"`
 }
 
 // NewDB returns a pointer to a new DB object with the provided configuration.
-func NewDB(ctx context.Context, cfg *DBConfig) *DB {
+func NewDB(cfg *DBConfig) *DB {
 	return &DB{
-		ctx: ctx,
 		cfg: cfg,
 	}
 }
 
 // Connect connects to the configured database and stores the returned client.
-func (db *DB) Connect() error {
-	c, err := connectedClient(db)
+func (db *DB) Connect(ctx context.Context) error {
+	c, err := connectedClient(ctx, db)
 	if err != nil {
 		return fmt.Errorf("connecting db client: %w", err)
 	}
@@ -47,8 +48,8 @@ func (db *DB) Connect() error {
 }
 
 // Disconnect closes all open connections.
-func (db *DB) Disconnect() error {
-	if err := db.conn.Disconnect(db.ctx); err != nil {
+func (db *DB) Disconnect(ctx context.Context) error {
+	if err := db.conn.Disconnect(ctx); err != nil {
 		return fmt.Errorf("disconnecting from db: %w", err)
 	}
 
@@ -57,10 +58,10 @@ func (db *DB) Disconnect() error {
 
 // Ping attempts to connect to the configured database to verify connectivity and that the database
 // is responding.
-func (db *DB) Ping() error {
+func (db *DB) Ping(ctx context.Context) error {
 	var res bson.M
 	err := db.conn.Database(db.cfg.Name).
-		RunCommand(db.ctx, bson.D{{Key: "ping", Value: 1}}).
+		RunCommand(ctx, bson.D{{Key: "ping", Value: 1}}).
 		Decode(&res)
 	if err != nil {
 		return fmt.Errorf("disconnecting db: %w", err)
@@ -69,7 +70,7 @@ func (db *DB) Ping() error {
 	return nil
 }
 
-func connectedClient(db *DB) (*mongo.Client, error) {
+func connectedClient(ctx context.Context, db *DB) (*mongo.Client, error) {
 	cred := options.Credential{
 		Username: db.cfg.User,
 		Password: db.cfg.Pass,
@@ -81,7 +82,7 @@ func connectedClient(db *DB) (*mongo.Client, error) {
 		SetServerAPIOptions(sAPI).
 		SetTimeout(3 * time.Second)
 
-	c, err := mongo.Connect(db.ctx, opts)
+	c, err := mongo.Connect(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("connecting to db at: %q: %w", db.cfg.Loc, err)
 	}
@@ -90,11 +91,14 @@ func connectedClient(db *DB) (*mongo.Client, error) {
 }
 
 // TODO: Generics?
-func (db *DB) Get(name string) (any, error) {
+func (db *DB) Get(ctx context.Context, name string) (any, error) {
 	f := bson.D{{Key: "name", Value: name}}
 
 	var res Club
-	err := db.coll.FindOne(db.ctx, f, nil).Decode(&res)
+	err := db.coll.FindOne(ctx, f, nil).Decode(&res)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("%w: %q", ErrClubNotFound, name)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("getting club from db: %w", err)
 	}
@@ -102,11 +106,29 @@ func (db *DB) Get(name string) (any, error) {
 	return &res, nil
 }
 
-func (db *DB) Player(club, name string) (*Player, error) {
+// Clubs returns every club in the collection.
+func (db *DB) Clubs(ctx context.Context) ([]*Club, error) {
+	cur, err := db.coll.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("finding clubs: %w", err)
+	}
+
+	cs := []*Club{}
+	if err := cur.All(ctx, &cs); err != nil {
+		return nil, fmt.Errorf("decoding clubs: %w", err)
+	}
+
+	return cs, nil
+}
+
+func (db *DB) Player(ctx context.Context, club, name string) (*Player, error) {
 	f := bson.D{{Key: "name", Value: club}, {Key: "players.$", Value: name}}
 
 	var res Player
-	err := db.coll.FindOne(db.ctx, f, nil).Decode(&res)
+	err := db.coll.FindOne(ctx, f, nil).Decode(&res)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("%w: %q", ErrPlayerNotFound, name)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("getting player from db: %w", err)
 	}
@@ -114,8 +136,11 @@ func (db *DB) Player(club, name string) (*Player, error) {
 	return &res, nil
 }
 
-func (db *DB) Create(data any) (any, error) {
-	res, err := db.coll.InsertOne(db.ctx, data)
+func (db *DB) Create(ctx context.Context, data any) (any, error) {
+	res, err := db.coll.InsertOne(ctx, data)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("db insert: %w", ErrAlreadyExists)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("db insert: %w", err)
 	}
@@ -123,13 +148,26 @@ func (db *DB) Create(data any) (any, error) {
 	return res.InsertedID, nil
 }
 
-func (db *DB) Update(c *Club) error {
+// Update writes the club's location, players, recruiting details (its capacity, recruitment state
+// and prospects) and its tags and notes.
+func (db *DB) Update(ctx context.Context, c *Club) error {
 	f := bson.D{{Key: "name", Value: c.Name}}
-	u := bson.D{{Key: "$set", Value: bson.D{{Key: "players", Value: c.Players}}}}
+	u := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "location", Value: c.Location},
+		{Key: "players", Value: c.Players},
+		{Key: "capacity", Value: c.Capacity},
+		{Key: "recruitment", Value: c.Recruitment},
+		{Key: "prospects", Value: c.Prospects},
+		{Key: "tags", Value: c.Tags},
+		{Key: "notes", Value: c.Notes},
+	}}}
 	o := options.FindOneAndUpdate().SetReturnDocument(options.After)
 
 	var res Club
-	err := db.coll.FindOneAndUpdate(db.ctx, f, u, o).Decode(&res)
+	err := db.coll.FindOneAndUpdate(ctx, f, u, o).Decode(&res)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return fmt.Errorf("db update: %w: %q", ErrClubNotFound, c.Name)
+	}
 	if err != nil {
 		return fmt.Errorf("db update: %w", err)
 	}
@@ -137,8 +175,63 @@ func (db *DB) Update(c *Club) error {
 	return nil
 }
 
-func (db *DB) Delete() error {
-	return fmt.Errorf("db delete unimplemented")
+// Delete removes the named club along with its players.
+func (db *DB) Delete(ctx context.Context, name string) error {
+	res, err := db.coll.DeleteOne(ctx, bson.D{{Key: "name", Value: name}})
+	if err != nil {
+		return fmt.Errorf("db delete: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("%w: %q", ErrClubNotFound, name)
+	}
+
+	return nil
+}
+
+// scheduleCollection holds the schedules of every guild, apart from the per guild club collections.
+const scheduleCollection = "schedules"
+
+// Schedules returns the schedules of every guild.
+func (db *DB) Schedules(ctx context.Context) (Schedules, error) {
+	cur, err := db.schedules().Find(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("finding schedules: %w", err)
+	}
+
+	ss := Schedules{}
+	if err := cur.All(ctx, &ss); err != nil {
+		return nil, fmt.Errorf("decoding schedules: %w", err)
+	}
+
+	return ss, nil
+}
+
+// CreateSchedule stores a new schedule.
+func (db *DB) CreateSchedule(ctx context.Context, s *Schedule) error {
+	if _, err := db.schedules().InsertOne(ctx, s); err != nil {
+		return fmt.Errorf("db insert: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSchedule removes the guild's schedule with the given id.
+func (db *DB) DeleteSchedule(ctx context.Context, guild string, id int) error {
+	f := bson.D{{Key: "guild", Value: guild}, {Key: "id", Value: id}}
+
+	res, err := db.schedules().DeleteOne(ctx, f)
+	if err != nil {
+		return fmt.Errorf("db delete: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("%w: %d", ErrScheduleNotFound, id)
+	}
+
+	return nil
+}
+
+func (db *DB) schedules() *mongo.Collection {
+	return db.conn.Database(db.cfg.Name).Collection(scheduleCollection)
 }
 
 func (db *DB) setCollection(coll string) {
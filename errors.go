@@ -0,0 +1,21 @@
+package witcharcana
+
+import "errors"
+
+// Errors wrapped by Clubs, DB and the data file functions so callers can tell failures apart without
+// matching messages. Invalid clubs, players and locations are reported with a *ValidationError.
+var (
+	// ErrClubNotFound is returned when a named club doesn't exist.
+	ErrClubNotFound = errors.New("club not found")
+	// ErrPlayerNotFound is returned when a named player doesn't exist in any club.
+	ErrPlayerNotFound = errors.New("player not found")
+	// ErrProspectNotFound is returned when a named prospect isn't on a club's recruiting list.
+	ErrProspectNotFound = errors.New("prospect not found")
+	// ErrEventNotFound is returned when an event with the given id doesn't exist.
+	ErrEventNotFound = errors.New("event not found")
+	// ErrScheduleNotFound is returned when a guild has no schedule with the given id.
+	ErrScheduleNotFound = errors.New("schedule not found")
+	// ErrAlreadyExists is returned when creating a club, player, prospect, event or file that's
+	// already there.
+	ErrAlreadyExists = errors.New("already exists")
+)
@@ -0,0 +1,119 @@
+package witcharcana
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	newClubs := func() *Clubs {
+		return &Clubs{
+			clubs: map[string]*Club{
+				"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee"}}},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		run      func(cs *Clubs) error
+		expected error
+	}{
+		{
+			name:     "get club",
+			run:      func(cs *Clubs) error { _, err := cs.Club(context.Background(), "SP"); return err },
+			expected: ErrClubNotFound,
+		},
+		{
+			name:     "update club",
+			run:      func(cs *Clubs) error { _, err := cs.UpdateClub(context.Background(), NewClub("SP", 1, 2)); return err },
+			expected: ErrClubNotFound,
+		},
+		{
+			name:     "remove club",
+			run:      func(cs *Clubs) error { return cs.RemoveClub(context.Background(), "SP") },
+			expected: ErrClubNotFound,
+		},
+		{
+			name:     "create club",
+			run:      func(cs *Clubs) error { return cs.CreateClub(context.Background(), &Club{Name: "AZA"}) },
+			expected: ErrAlreadyExists,
+		},
+		{
+			name:     "get player",
+			run:      func(cs *Clubs) error { _, err := cs.Player(context.Background(), "Hoeb"); return err },
+			expected: ErrPlayerNotFound,
+		},
+		{
+			name: "create player in missing club",
+			run: func(cs *Clubs) error {
+				_, err := cs.CreatePlayer(context.Background(), "SP", &Player{Name: "Hoeb"})
+				return err
+			},
+			expected: ErrClubNotFound,
+		},
+		{
+			name: "create existing player",
+			run: func(cs *Clubs) error {
+				_, err := cs.CreatePlayer(context.Background(), "AZA", &Player{Name: "Fayeee"})
+				return err
+			},
+			expected: ErrAlreadyExists,
+		},
+		{
+			name: "update player",
+			run: func(cs *Clubs) error {
+				_, err := cs.UpdatePlayer(context.Background(), &Player{Name: "Hoeb"})
+				return err
+			},
+			expected: ErrPlayerNotFound,
+		},
+		{
+			name:     "remove prospect",
+			run:      func(cs *Clubs) error { return cs.RemoveProspect(context.Background(), "AZA", "Hoeb") },
+			expected: ErrProspectNotFound,
+		},
+		{
+			name:     "move player",
+			run:      func(cs *Clubs) error { _, err := cs.MovePlayer(context.Background(), "Hoeb", "AZA"); return err },
+			expected: ErrPlayerNotFound,
+		},
+		{
+			name:     "move player to missing club",
+			run:      func(cs *Clubs) error { _, err := cs.MovePlayer(context.Background(), "Fayeee", "SP"); return err },
+			expected: ErrClubNotFound,
+		},
+		{
+			name:     "remove player",
+			run:      func(cs *Clubs) error { return cs.RemovePlayer(context.Background(), "Hoeb") },
+			expected: ErrPlayerNotFound,
+		},
+		{
+			name: "init existing data file",
+			run: func(cs *Clubs) error {
+				loc := filepath.Join(t.TempDir(), "clubs.json")
+				if err := InitDataFile(loc); err != nil {
+					return err
+				}
+				return InitDataFile(loc)
+			},
+			expected: ErrAlreadyExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.run(newClubs())
+
+			assert.True(t, errors.Is(err, tc.expected), "got %v", err)
+		})
+	}
+
+	_, err := newClubs().CreatePlayer(context.Background(), "AZA", &Player{Name: "Ho eb!"})
+	var ve *ValidationError
+	assert.True(t, errors.As(err, &ve))
+}
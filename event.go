@@ -0,0 +1,305 @@
+package witcharcana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EventDateLayout is the layout event dates are written and parsed in.
+const EventDateLayout = "2006-01-02"
+
+// EventType is the kind of an event. Besides the common types any tag-like name can be used, ie:
+// kvk.
+type EventType string
+
+const (
+	// EventClubWar is a war between clubs.
+	EventClubWar EventType = "club-war"
+	// EventHiveDefense is a defense of a club's hive.
+	EventHiveDefense EventType = "hive-defense"
+)
+
+// ParseEventType returns the event type named by s.
+func ParseEventType(s string) (EventType, error) {
+	et := EventType(strings.ToLower(strings.TrimSpace(s)))
+	if fe := validateEventType("type", et); fe != nil {
+		return "", fe
+	}
+
+	return et, nil
+}
+
+// Events is a collection of events.
+type Events []*Event
+
+// Event is an organized in-game event the players of one or more clubs take part in.
+type Event struct {
+	// ID is made from the event's type, date and clubs, ie: club-war-2026-10-19-AZA-SP.
+	ID    string    `json:"id"`
+	Type  EventType `json:"type"`
+	Date  time.Time `json:"date"`
+	Clubs []string  `json:"clubs"`
+	// Attendance holds a record for each player who took part. Players of the event's clubs without
+	// one missed it.
+	Attendance []*Attendance `json:"attendance,omitempty"`
+}
+
+// Attendance records a player taking part in an event.
+type Attendance struct {
+	Player string `json:"player"`
+	// Club is the club the player was in when they took part.
+	Club  string `json:"club"`
+	Score int64  `json:"score,omitempty"`
+}
+
+// NewEvent returns a pointer to a new event of the given type on the day of date for the named clubs.
+// The clubs are trimmed and sorted, then made part of its ID so events of the same type on one day
+// don't collide while the same clubs given in another order still do.
+func NewEvent(et EventType, date time.Time, clubs ...string) *Event {
+	d := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	var cs []string
+	for _, c := range clubs {
+		cs = append(cs, strings.TrimSpace(c))
+	}
+	sort.Strings(cs)
+
+	return &Event{
+		ID:    strings.Join(append([]string{string(et), d.Format(EventDateLayout)}, cs...), "-"),
+		Type:  et,
+		Date:  d,
+		Clubs: cs,
+	}
+}
+
+// has returns true when the named club took part in the event.
+func (e *Event) has(clubName string) bool {
+	for _, c := range e.Clubs {
+		if c == clubName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// attendance returns the position of the named player's attendance record or -1 when they don't
+// have one.
+func (e *Event) attendance(name string) int {
+	for i, a := range e.Attendance {
+		if a.Player == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Validate checks the event's type, date, clubs and attendance records.
+func (e *Event) Validate() error {
+	return validationError("event", e.ID, e.validate())
+}
+
+func (e *Event) validate() []*FieldError {
+	var fes []*FieldError
+	if fe := validateEventType("type", e.Type); fe != nil {
+		fes = append(fes, fe)
+	}
+	if e.Date.IsZero() {
+		fes = append(fes, &FieldError{Field: "date", Reason: "required"})
+	}
+
+	if len(e.Clubs) == 0 {
+		fes = append(fes, &FieldError{Field: "clubs", Reason: "required"})
+	}
+	for _, c := range e.Clubs {
+		fes = append(fes, validateName("club", c)...)
+	}
+
+	for _, a := range e.Attendance {
+		fes = append(fes, validateName(fmt.Sprintf("attendance %q player", a.Player), a.Player)...)
+		if a.Score < 0 {
+			fes = append(fes, &FieldError{
+				Field:  fmt.Sprintf("attendance %q score", a.Player),
+				Reason: fmt.Sprintf("cannot be negative. got %d", a.Score),
+			})
+		}
+	}
+
+	return fes
+}
+
+func validateEventType(field string, et EventType) *FieldError {
+	if et == "" {
+		return &FieldError{Field: field, Reason: "required"}
+	}
+
+	return validateLabel(field, "", string(et))
+}
+
+// Events returns every event ordered by date.
+func (cs *Clubs) Events() Events {
+	return cs.events
+}
+
+// Event returns the event with the given id.
+func (cs *Clubs) Event(id string) (*Event, error) {
+	if i := event(cs.events, id); i >= 0 {
+		return cs.events[i], nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrEventNotFound, id)
+}
+
+// CreateEvent adds an event for clubs that already exist. Events are only kept in data files.
+func (cs *Clubs) CreateEvent(ctx context.Context, e *Event) error {
+	if cs.db != nil {
+		return errors.New("events are only kept in data files")
+	}
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	for _, c := range e.Clubs {
+		if _, err := cs.Club(ctx, c); err != nil {
+			return fmt.Errorf("getting club: %w", err)
+		}
+	}
+	if event(cs.events, e.ID) >= 0 {
+		return fmt.Errorf("event %q %w", e.ID, ErrAlreadyExists)
+	}
+
+	cs.events = append(cs.events, e)
+	sort.SliceStable(cs.events, func(i, j int) bool { return cs.events[i].Date.Before(cs.events[j].Date) })
+
+	return nil
+}
+
+// RecordAttendance records the named player taking part in an event with an optional score. The
+// player must be in one of the event's clubs. Recording a player again replaces their score.
+func (cs *Clubs) RecordAttendance(ctx context.Context, id, name string, score int64) (*Attendance, error) {
+	if score < 0 {
+		return nil, validationError("attendance", name, []*FieldError{{
+			Field:  "score",
+			Reason: fmt.Sprintf("cannot be negative. got %d", score),
+		}})
+	}
+
+	e, err := cs.Event(id)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := cs.Player(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !e.has(p.Club) {
+		return nil, fmt.Errorf("player %q is in club %q which isn't part of event %q", name, p.Club, id)
+	}
+
+	a := &Attendance{Player: p.Name, Club: p.Club, Score: score}
+
+	if i := e.attendance(name); i >= 0 {
+		e.Attendance[i] = a
+	} else {
+		e.Attendance = append(e.Attendance, a)
+	}
+
+	return a, nil
+}
+
+// ParticipationOptions chooses the events players' participation is worked out from.
+type ParticipationOptions struct {
+	// Club limits the report to a single club's players.
+	Club string
+	// Type only counts events of this type when given.
+	Type EventType
+}
+
+// Participation lists how often players took part in the events they could have.
+type Participation []*PlayerParticipation
+
+// PlayerParticipation is a player along with the events they could have taken part in, how many they
+// did and their total score across them.
+type PlayerParticipation struct {
+	*Player
+	Events   int     `json:"events"`
+	Attended int     `json:"attended"`
+	Rate     float64 `json:"rate"`
+	Score    int64   `json:"score,omitempty"`
+}
+
+// Percent returns the player's participation rate as a percentage.
+func (pp *PlayerParticipation) Percent() float64 {
+	return pp.Rate * 100
+}
+
+// Participation returns the participation of each player, ordered by club name. An event counts for
+// a player when their current club was part of it or they took part in it. Players without any
+// events are left out.
+func (cs *Clubs) Participation(ctx context.Context, opts *ParticipationOptions) (Participation, error) {
+	clubs, err := selectClubs(ctx, cs, opts.Club)
+	if err != nil {
+		return nil, err
+	}
+
+	pa := Participation{}
+	for _, c := range clubs {
+		for _, p := range c.Players {
+			pp := participation(cs.events, opts.Type, c.Name, p.Name)
+			if pp.Events == 0 {
+				continue
+			}
+
+			rp := *p
+			rp.Club = c.Name
+			pp.Player = &rp
+			pa = append(pa, pp)
+		}
+	}
+
+	return pa, nil
+}
+
+// participation counts the events of the given type, or all of them, the named player of clubName
+// could have and did take part in.
+func participation(es Events, et EventType, clubName, name string) *PlayerParticipation {
+	pp := &PlayerParticipation{}
+	for _, e := range es {
+		if et != "" && e.Type != et {
+			continue
+		}
+
+		i := e.attendance(name)
+		if i < 0 && !e.has(clubName) {
+			continue
+		}
+
+		pp.Events++
+		if i >= 0 {
+			pp.Attended++
+			pp.Score += e.Attendance[i].Score
+		}
+	}
+	if pp.Events > 0 {
+		pp.Rate = float64(pp.Attended) / float64(pp.Events)
+	}
+
+	return pp
+}
+
+// event returns the position of the event with the given id or -1 when there isn't one.
+func event(es Events, id string) int {
+	for i, e := range es {
+		if e.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,318 @@
+package witcharcana
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEventType(t *testing.T) {
+	et, err := ParseEventType(" Club-War")
+	assert.NoError(t, err)
+	assert.Equal(t, EventClubWar, et)
+
+	_, err = ParseEventType("club war")
+	assert.EqualError(t, err, `type "club war" cannot contain ' '`)
+}
+
+func TestCsCreateEvent(t *testing.T) {
+	testCases := []struct {
+		name        string
+		clubs       Clubs
+		event       *Event
+		expectedIDs []string
+		expectedErr error
+	}{
+		{
+			name: "created in date order",
+			clubs: Clubs{
+				clubs: map[string]*Club{"AZA": {Name: "AZA"}, "404": {Name: "404"}},
+				events: Events{
+					{ID: "club-war-2026-10-05", Type: EventClubWar, Date: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), Clubs: []string{"AZA"}},
+					{ID: "hive-defense-2026-10-12", Type: EventHiveDefense, Date: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), Clubs: []string{"AZA"}},
+				},
+			},
+			event:       NewEvent("kvk", time.Date(2026, 10, 8, 20, 30, 0, 0, time.UTC), "AZA", "404"),
+			expectedIDs: []string{"club-war-2026-10-05", "kvk-2026-10-08-404-AZA", "hive-defense-2026-10-12"},
+		},
+		{
+			name: "same day for other clubs",
+			clubs: Clubs{
+				clubs: map[string]*Club{"AZA": {Name: "AZA"}, "SP": {Name: "SP"}, "CNT": {Name: "CNT"}},
+				events: Events{
+					{ID: "club-war-2026-10-19-AZA-SP", Type: EventClubWar, Date: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), Clubs: []string{"AZA", "SP"}},
+				},
+			},
+			event:       NewEvent(EventClubWar, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), "AZA", "CNT"),
+			expectedIDs: []string{"club-war-2026-10-19-AZA-SP", "club-war-2026-10-19-AZA-CNT"},
+		},
+		{
+			name: "already exists",
+			clubs: Clubs{
+				clubs: map[string]*Club{"AZA": {Name: "AZA"}},
+				events: Events{
+					{ID: "club-war-2026-10-05-AZA", Type: EventClubWar, Date: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), Clubs: []string{"AZA"}},
+				},
+			},
+			event:       NewEvent(EventClubWar, time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), "AZA"),
+			expectedErr: fmt.Errorf(`event "club-war-2026-10-05-AZA" already exists`),
+		},
+		{
+			name: "same clubs in another order",
+			clubs: Clubs{
+				clubs: map[string]*Club{"AZA": {Name: "AZA"}, "SP": {Name: "SP"}},
+				events: Events{
+					{ID: "club-war-2026-10-19-AZA-SP", Type: EventClubWar, Date: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), Clubs: []string{"AZA", "SP"}},
+				},
+			},
+			event:       NewEvent(EventClubWar, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), "SP", " AZA"),
+			expectedErr: fmt.Errorf(`event "club-war-2026-10-19-AZA-SP" already exists`),
+		},
+		{
+			name: "club not found",
+			clubs: Clubs{
+				clubs: map[string]*Club{"AZA": {Name: "AZA"}},
+			},
+			event:       NewEvent(EventClubWar, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), "AZA", "CNT"),
+			expectedErr: fmt.Errorf(`getting club: club not found: "CNT"`),
+		},
+		{
+			name:        "invalid",
+			event:       &Event{ID: "nope", Type: EventClubWar},
+			expectedErr: fmt.Errorf(`invalid event "nope": date required; clubs required`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.clubs.CreateEvent(context.Background(), tc.event)
+
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+
+			var ids []string
+			for _, e := range tc.clubs.Events() {
+				ids = append(ids, e.ID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
+
+func TestCsRecordAttendance(t *testing.T) {
+	ctx := context.Background()
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee"}}},
+			"SP":  {Name: "SP", Players: Players{{Name: "Quinoa"}, {Name: "Hoeb"}}},
+			"404": {Name: "404", Players: Players{{Name: "LoverOnyx"}}},
+		},
+		events: Events{
+			{ID: "club-war-2026-10-05", Type: EventClubWar, Date: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC),
+				Clubs: []string{"AZA", "SP"},
+				Attendance: []*Attendance{
+					{Player: "Fayeee", Club: "AZA", Score: 1200},
+					{Player: "Quinoa", Club: "SP", Score: 300},
+				},
+			},
+		},
+	}
+
+	a, err := cs.RecordAttendance(ctx, "club-war-2026-10-05", "Hoeb", 50)
+	assert.NoError(t, err)
+	assert.Equal(t, &Attendance{Player: "Hoeb", Club: "SP", Score: 50}, a)
+
+	// recording again replaces the score
+	_, err = cs.RecordAttendance(ctx, "club-war-2026-10-05", "Fayeee", 1500)
+	assert.NoError(t, err)
+	e, _ := cs.Event("club-war-2026-10-05")
+	assert.Equal(t, []*Attendance{
+		{Player: "Fayeee", Club: "AZA", Score: 1500},
+		{Player: "Quinoa", Club: "SP", Score: 300},
+		{Player: "Hoeb", Club: "SP", Score: 50},
+	}, e.Attendance)
+
+	_, err = cs.RecordAttendance(ctx, "club-war-2026-10-05", "LoverOnyx", 0)
+	assert.EqualError(t, err, `player "LoverOnyx" is in club "404" which isn't part of event "club-war-2026-10-05"`)
+
+	_, err = cs.RecordAttendance(ctx, "kvk-2026-10-19", "Fayeee", 0)
+	assert.True(t, errors.Is(err, ErrEventNotFound))
+
+	_, err = cs.RecordAttendance(ctx, "club-war-2026-10-05", "M4rs", 0)
+	assert.True(t, errors.Is(err, ErrPlayerNotFound))
+
+	_, err = cs.RecordAttendance(ctx, "club-war-2026-10-05", "Fayeee", -1)
+	assert.EqualError(t, err, `invalid attendance "Fayeee": score cannot be negative. got -1`)
+}
+
+func TestCsParticipation(t *testing.T) {
+	testCases := []struct {
+		name        string
+		clubs       Clubs
+		opts        *ParticipationOptions
+		expected    map[string][3]int64
+		expectedErr error
+	}{
+		{
+			name: "all events",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee"}, {Name: "Richard"}}},
+					"SP":  {Name: "SP", Players: Players{{Name: "Quinoa"}, {Name: "Hoeb"}}},
+					"404": {Name: "404", Players: Players{{Name: "LoverOnyx"}}},
+				},
+				events: Events{
+					{ID: "club-war-2026-10-05", Type: EventClubWar, Clubs: []string{"AZA", "SP"},
+						Attendance: []*Attendance{
+							{Player: "Fayeee", Club: "AZA", Score: 1200},
+							{Player: "Quinoa", Club: "SP", Score: 300},
+						},
+					},
+					{ID: "hive-defense-2026-10-12", Type: EventHiveDefense, Clubs: []string{"AZA"},
+						Attendance: []*Attendance{{Player: "Fayeee", Club: "AZA"}, {Player: "Richard", Club: "AZA"}},
+					},
+				},
+			},
+			opts: &ParticipationOptions{},
+			expected: map[string][3]int64{
+				"Fayeee":  {2, 2, 1200},
+				"Richard": {2, 1, 0},
+				"Quinoa":  {1, 1, 300},
+				"Hoeb":    {1, 0, 0},
+			},
+		},
+		{
+			name: "single type and club",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee"}, {Name: "Richard"}}},
+				},
+				events: Events{
+					{ID: "club-war-2026-10-05", Type: EventClubWar, Clubs: []string{"AZA"},
+						Attendance: []*Attendance{{Player: "Fayeee", Club: "AZA", Score: 1200}},
+					},
+					{ID: "hive-defense-2026-10-12", Type: EventHiveDefense, Clubs: []string{"AZA"},
+						Attendance: []*Attendance{{Player: "Fayeee", Club: "AZA"}, {Player: "Richard", Club: "AZA"}},
+					},
+				},
+			},
+			opts:     &ParticipationOptions{Club: "AZA", Type: EventHiveDefense},
+			expected: map[string][3]int64{"Fayeee": {1, 1, 0}, "Richard": {1, 1, 0}},
+		},
+		{
+			name: "club not found",
+			clubs: Clubs{
+				clubs: map[string]*Club{"AZA": {Name: "AZA"}},
+			},
+			opts:        &ParticipationOptions{Club: "CNT"},
+			expectedErr: fmt.Errorf(`getting club: club not found: "CNT"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.clubs.Participation(context.Background(), tc.opts)
+
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+
+			counts := map[string][3]int64{}
+			for _, pp := range actual {
+				counts[pp.Name] = [3]int64{int64(pp.Events), int64(pp.Attended), pp.Score}
+			}
+			assert.Equal(t, tc.expected, counts)
+		})
+	}
+}
+
+func TestParticipationAfterMove(t *testing.T) {
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"SP":  {Name: "SP", Players: Players{{Name: "Quinoa"}}},
+			"404": {Name: "404"},
+		},
+		events: Events{
+			{ID: "club-war-2026-10-05", Type: EventClubWar, Clubs: []string{"SP"},
+				Attendance: []*Attendance{{Player: "Quinoa", Club: "SP", Score: 300}},
+			},
+		},
+	}
+	_, err := cs.MovePlayer(context.Background(), "Quinoa", "404")
+	assert.NoError(t, err)
+
+	pa, err := cs.Participation(context.Background(), &ParticipationOptions{Club: "404"})
+	assert.NoError(t, err)
+	assert.Len(t, pa, 1, "events attended in a previous club still count")
+	assert.Equal(t, "Quinoa", pa[0].Name)
+	assert.Equal(t, 1.0, pa[0].Rate)
+}
+
+func TestEventsSaved(t *testing.T) {
+	loc := filepath.Join(t.TempDir(), "clubs.json")
+	cs := &Clubs{
+		clubs: map[string]*Club{"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee"}}}},
+		events: Events{
+			{ID: "club-war-2026-10-05", Type: EventClubWar, Date: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC),
+				Clubs:      []string{"AZA"},
+				Attendance: []*Attendance{{Player: "Fayeee", Club: "AZA", Score: 1200}},
+			},
+		},
+		dataLoc: loc,
+	}
+	assert.NoError(t, cs.Save())
+
+	loaded := &Clubs{}
+	assert.NoError(t, loaded.LoadData(loc))
+	assert.Equal(t, cs.events, loaded.Events())
+}
+
+func TestEventTemplates(t *testing.T) {
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee"}, {Name: "Richard"}}},
+		},
+		events: Events{
+			{ID: "club-war-2026-10-05", Type: EventClubWar, Date: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC),
+				Clubs: []string{"AZA", "SP"},
+				Attendance: []*Attendance{
+					{Player: "Fayeee", Club: "AZA", Score: 1200},
+					{Player: "Quinoa", Club: "SP", Score: 300},
+				},
+			},
+			{ID: "hive-defense-2026-10-12", Type: EventHiveDefense, Date: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC),
+				Clubs:      []string{"AZA"},
+				Attendance: []*Attendance{{Player: "Fayeee", Club: "AZA"}, {Player: "Richard", Club: "AZA"}},
+			},
+		},
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, NewTemplates("").Render(&b, cs.events[0]))
+	assert.Equal(t, "Event: club-war-2026-10-05\n"+
+		"    Type: club-war\n"+
+		"    Date: 2026-10-05\n"+
+		"    Clubs: AZA, SP\n"+
+		"    Attendance:\n"+
+		"      Fayeee               AZA      1,200\n"+
+		"      Quinoa               SP         300\n", b.String())
+
+	pa, err := cs.Participation(context.Background(), &ParticipationOptions{Club: "AZA"})
+	assert.NoError(t, err)
+	b.Reset()
+	assert.NoError(t, NewTemplates("").Render(&b, pa))
+	assert.Equal(t, "Participation:\n"+
+		"  Fayeee               AZA     2/2   100%    1,200\n"+
+		"  Richard              AZA     1/2    50%\n", b.String())
+}
@@ -0,0 +1,145 @@
+package witcharcana
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ExportFormat is a file format clubs and players can be exported to.
+type ExportFormat string
+
+const (
+	ExportCSV   ExportFormat = "csv"
+	ExportXLSX  ExportFormat = "xlsx"
+	ExportJSONL ExportFormat = "jsonl"
+)
+
+// exportHeader lists the exported columns in order. They match the fields read by DecodeCSV so
+// exported csvs can be imported again.
+var exportHeader = []string{"club", "name", "level", "might", "location", "in_hive", "tags"}
+
+// ExportRow is a flat representation of a player and their club.
+type ExportRow struct {
+	Club     string `json:"club"`
+	Name     string `json:"name"`
+	Level    int    `json:"level,omitempty"`
+	Might    int64  `json:"might,omitempty"`
+	Location string `json:"location,omitempty"`
+	InHive   bool   `json:"in_hive"`
+	// Inactive is only written to json lines as imports don't mark players inactive.
+	Inactive bool   `json:"inactive,omitempty"`
+	Tags     string `json:"tags,omitempty"`
+}
+
+// ParseExportFormat returns the ExportFormat matching s.
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch f := ExportFormat(s); f {
+	case ExportCSV, ExportXLSX, ExportJSONL:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("unknown export format %q. options: %v", s, []ExportFormat{ExportCSV, ExportXLSX, ExportJSONL})
+}
+
+// ExportRows returns a row for every player, ordered by club name. When clubName is provided only
+// that club's players are returned, and when tags are only players with every one of them.
+func (cs *Clubs) ExportRows(ctx context.Context, clubName string, tags ...string) ([]*ExportRow, error) {
+	clubs, err := selectClubs(ctx, cs, clubName)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []*ExportRow{}
+	for _, c := range clubs {
+		for _, p := range c.Tagged(tags...).Players {
+			loc, err := p.Location.MarshalCSV()
+			if err != nil {
+				return nil, fmt.Errorf("marshaling location of player %q: %w", p.Name, err)
+			}
+
+			rows = append(rows, &ExportRow{
+				Club:     c.Name,
+				Name:     p.Name,
+				Level:    p.Level,
+				Might:    p.Might,
+				Location: loc,
+				InHive:   p.InHive,
+				Inactive: p.Inactive,
+				Tags:     strings.Join(p.Tags, ","),
+			})
+		}
+	}
+
+	return rows, nil
+}
+
+// WriteExport writes rows to w in the given format.
+func WriteExport(w io.Writer, format ExportFormat, rows []*ExportRow) error {
+	switch format {
+	case ExportCSV:
+		return writeCSVExport(w, rows)
+	case ExportXLSX:
+		return writeXLSXExport(w, rows)
+	case ExportJSONL:
+		return writeJSONLExport(w, rows)
+	}
+
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+// cells returns the row's values in exportHeader order. Zero numbers are left empty.
+func (r *ExportRow) cells() []string {
+	var level, might string
+	if r.Level != 0 {
+		level = strconv.Itoa(r.Level)
+	}
+	if r.Might != 0 {
+		might = strconv.FormatInt(r.Might, 10)
+	}
+
+	return []string{
+		r.Club,
+		r.Name,
+		level,
+		might,
+		r.Location,
+		strconv.FormatBool(r.InHive),
+		r.Tags,
+	}
+}
+
+func writeCSVExport(w io.Writer, rows []*ExportRow) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(exportHeader); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+	for _, r := range rows {
+		if err := cw.Write(r.cells()); err != nil {
+			return fmt.Errorf("writing player %q: %w", r.Name, err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("flushing: %w", err)
+	}
+
+	return nil
+}
+
+func writeJSONLExport(w io.Writer, rows []*ExportRow) error {
+	enc := json.NewEncoder(w)
+	for _, r := range rows {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("writing player %q: %w", r.Name, err)
+		}
+	}
+
+	return nil
+}
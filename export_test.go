@@ -0,0 +1,206 @@
+package witcharcana
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCsExportRows(t *testing.T) {
+	testCases := []struct {
+		name        string
+		clubs       Clubs
+		clubName    string
+		tags        []string
+		expected    []*ExportRow
+		expectedErr error
+	}{
+		{
+			name: "all clubs",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{
+						{Name: "Fayeee", Level: 18, Might: 70265122, Location: &Location{X: 303, Y: 733}, Tags: Tags{"farm", "kvk-captain"}},
+						{Name: "Richard", Inactive: true},
+					}},
+					"404": {Name: "404", Location: &Location{X: 123, Y: 456}, Players: Players{
+						{Name: "LoverOnyx", Location: &Location{X: 123, Y: 457}, InHive: true},
+					}},
+				},
+			},
+			expected: []*ExportRow{
+				{Club: "404", Name: "LoverOnyx", Location: "123:457", InHive: true},
+				{Club: "AZA", Name: "Fayeee", Level: 18, Might: 70265122, Location: "303:733", Tags: "farm,kvk-captain"},
+				{Club: "AZA", Name: "Richard", Inactive: true},
+			},
+		},
+		{
+			name: "single club",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee"}}},
+					"404": {Name: "404", Players: Players{
+						{Name: "LoverOnyx", Location: &Location{X: 123, Y: 457}, InHive: true},
+					}},
+				},
+			},
+			clubName: "404",
+			expected: []*ExportRow{
+				{Club: "404", Name: "LoverOnyx", Location: "123:457", InHive: true},
+			},
+		},
+		{
+			name: "tagged",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{
+						{Name: "Fayeee", Level: 18, Might: 70265122, Location: &Location{X: 303, Y: 733}, Tags: Tags{"farm", "kvk-captain"}},
+						{Name: "Richard", Inactive: true},
+					}},
+				},
+			},
+			tags: []string{"farm"},
+			expected: []*ExportRow{
+				{Club: "AZA", Name: "Fayeee", Level: 18, Might: 70265122, Location: "303:733", Tags: "farm,kvk-captain"},
+			},
+		},
+		{
+			name: "club not found",
+			clubs: Clubs{
+				clubs: map[string]*Club{"AZA": {Name: "AZA"}},
+			},
+			clubName:    "SP",
+			expectedErr: fmt.Errorf(`getting club: club not found: "SP"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.clubs.ExportRows(context.Background(), tc.clubName, tc.tags...)
+
+			assert.Equal(t, tc.expected, actual)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWriteExport(t *testing.T) {
+	rows := []*ExportRow{
+		{Club: "404", Name: "LoverOnyx", Location: "123:457", InHive: true},
+		{Club: "AZA", Name: "Fayeee", Level: 18, Might: 70265122, Location: "303:733", Tags: "farm,kvk-captain"},
+		{Club: "AZA", Name: "Richard", Inactive: true},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var b bytes.Buffer
+		assert.NoError(t, WriteExport(&b, ExportCSV, rows))
+
+		expected := "club,name,level,might,location,in_hive,tags\n" +
+			"404,LoverOnyx,,,123:457,true,\n" +
+			"AZA,Fayeee,18,70265122,303:733,false,\"farm,kvk-captain\"\n" +
+			"AZA,Richard,,,,false,\n"
+		assert.Equal(t, expected, b.String())
+
+		// exports must be readable by the importer
+		res, err := DecodeCSV(&b, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, Players{
+			{Name: "LoverOnyx", Club: "404", Location: &Location{X: 123, Y: 457}, InHive: true},
+			{Name: "Fayeee", Club: "AZA", Level: 18, Might: 70265122, Location: &Location{X: 303, Y: 733}, Tags: Tags{"farm", "kvk-captain"}},
+			{Name: "Richard", Club: "AZA"},
+		}, res.Players)
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		var b bytes.Buffer
+		assert.NoError(t, WriteExport(&b, ExportJSONL, rows))
+
+		expected := `{"club":"404","name":"LoverOnyx","location":"123:457","in_hive":true}` + "\n" +
+			`{"club":"AZA","name":"Fayeee","level":18,"might":70265122,"location":"303:733","in_hive":false,"tags":"farm,kvk-captain"}` + "\n" +
+			`{"club":"AZA","name":"Richard","in_hive":false,"inactive":true}` + "\n"
+		assert.Equal(t, expected, b.String())
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		var b bytes.Buffer
+		assert.NoError(t, WriteExport(&b, ExportXLSX, rows))
+
+		zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+		assert.NoError(t, err)
+
+		var sheet []byte
+		for _, f := range zr.File {
+			if f.Name != "xl/worksheets/sheet1.xml" {
+				continue
+			}
+
+			rc, err := f.Open()
+			assert.NoError(t, err)
+			sheet, err = io.ReadAll(rc)
+			assert.NoError(t, err)
+			rc.Close()
+		}
+
+		assert.Len(t, zr.File, 5)
+		assert.Contains(t, string(sheet), `<c r="B3" t="inlineStr"><is><t>Fayeee</t></is></c>`)
+		assert.Contains(t, string(sheet), `<c r="D3"><v>70265122</v></c>`)
+		assert.Contains(t, string(sheet), `<c r="F2" t="b"><v>1</v></c>`)
+	})
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"AZA": {Name: "AZA", Players: Players{
+				{Name: "Fayeee", Level: 18, Might: 70265122, Location: &Location{X: 303, Y: 733}, Tags: Tags{"farm"}},
+				{Name: "Richard", Inactive: true},
+			}},
+			"404": {Name: "404", Players: Players{{Name: "LoverOnyx", InHive: true}}},
+		},
+	}
+	expected := cs.clone()
+
+	rows, err := cs.ExportRows(ctx, "")
+	assert.NoError(t, err)
+	var b bytes.Buffer
+	assert.NoError(t, WriteExport(&b, ExportCSV, rows))
+	res, err := DecodeCSV(&b, nil)
+	assert.NoError(t, err)
+
+	// importing an unedited export changes nothing, keeping inactive players inactive
+	chs, err := cs.BulkUpdatePlayers(ctx, res.Players, SyncNone)
+	assert.NoError(t, err)
+	assert.True(t, chs.Empty(), chs)
+	assert.Equal(t, expected.clubs, cs.clubs)
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for i, expected := range map[int]string{0: "A", 6: "G", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, expected, xlsxColumn(i))
+	}
+}
+
+func TestLocationMarshalCSV(t *testing.T) {
+	var nl *Location
+	s, err := nl.MarshalCSV()
+	assert.NoError(t, err)
+	assert.Equal(t, "", s)
+
+	s, err = (&Location{X: 303, Y: 733}).MarshalCSV()
+	assert.NoError(t, err)
+	assert.Equal(t, "303:733", s)
+
+	var l Location
+	assert.NoError(t, l.UnmarshalCSV(s))
+	assert.Equal(t, Location{X: 303, Y: 733}, l)
+}
@@ -0,0 +1,115 @@
+package witcharcana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCSVSize limits how much of a fetched csv is read.
+const maxCSVSize = 10 << 20
+
+// ErrNotModified is returned when a fetched csv hasn't changed since it was last fetched.
+var ErrNotModified = errors.New("csv not modified")
+
+// CSVFetcher retrieves player csvs from http(s) urls such as published spreadsheets. The ETag of each
+// fetched url is remembered so unchanged csvs aren't downloaded and imported again.
+type CSVFetcher struct {
+	client *http.Client
+
+	mu    sync.Mutex
+	etags map[string]string
+}
+
+// NewCSVFetcher returns a pointer to a new CSVFetcher whose requests give up after timeout.
+func NewCSVFetcher(timeout time.Duration) *CSVFetcher {
+	return &CSVFetcher{
+		client: &http.Client{Timeout: timeout},
+		etags:  map[string]string{},
+	}
+}
+
+// IsURL returns true when loc is an http or https url rather than a file location.
+func IsURL(loc string) bool {
+	return strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://")
+}
+
+// Fetch downloads and decodes the csv at rawURL. ErrNotModified is returned when the server reports
+// the csv is unchanged since the last successful fetch.
+func (f *CSVFetcher) Fetch(ctx context.Context, rawURL string, opts *CSVOptions) (*CSVResult, error) {
+	if !IsURL(rawURL) {
+		return nil, fmt.Errorf("url %q must use http or https", rawURL)
+	}
+	u := SheetCSVURL(rawURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	f.mu.Lock()
+	etag := f.etags[u]
+	f.mu.Unlock()
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching csv: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, ErrNotModified
+	default:
+		return nil, fmt.Errorf("fetching csv: unexpected status %q", resp.Status)
+	}
+
+	res, err := DecodeCSV(io.LimitReader(resp.Body, maxCSVSize), opts)
+	if err != nil {
+		return nil, fmt.Errorf("decoding: %w", err)
+	}
+
+	// only remember the etag once the csv has been read successfully.
+	if et := resp.Header.Get("ETag"); et != "" {
+		f.mu.Lock()
+		f.etags[u] = et
+		f.mu.Unlock()
+	}
+
+	return res, nil
+}
+
+// SheetCSVURL converts a google sheets edit link into the link exporting that sheet as a csv. Any
+// other url, including already published csv links, is returned unchanged.
+func SheetCSVURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host != "docs.google.com" {
+		return rawURL
+	}
+
+	// edit links look like /spreadsheets/d/<id>/edit
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "spreadsheets" || parts[1] != "d" || parts[3] != "edit" {
+		return rawURL
+	}
+
+	gid := u.Query().Get("gid")
+	if frag, err := url.ParseQuery(u.Fragment); err == nil && frag.Get("gid") != "" {
+		gid = frag.Get("gid")
+	}
+	if gid == "" {
+		gid = "0"
+	}
+
+	return fmt.Sprintf("https://docs.google.com/spreadsheets/d/%s/export?format=csv&gid=%s", parts[2], gid)
+}
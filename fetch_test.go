@@ -0,0 +1,114 @@
+package witcharcana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVFetcherFetch(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		switch r.URL.Path {
+		case "/players.csv":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeFile(w, r, "./testdata/players.csv")
+		case "/slow.csv":
+			time.Sleep(200 * time.Millisecond)
+			http.ServeFile(w, r, "./testdata/players.csv")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	f := NewCSVFetcher(50 * time.Millisecond)
+
+	t.Run("fetched and decoded", func(t *testing.T) {
+		res, err := f.Fetch(ctx, srv.URL+"/players.csv", nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Players{
+			{Name: "DireVoidCat", Club: "404", Level: 16, Might: 51848883},
+			{Name: "Fayeee", Club: "AZA", Level: 18, Might: 70265122, Location: &Location{X: 303, Y: 733}},
+			{Name: "Quinoa", Club: "SP", Level: 16, Location: &Location{X: 12, Y: 34}, InHive: true},
+		}, res.Players)
+	})
+
+	t.Run("unchanged csv not fetched again", func(t *testing.T) {
+		before := requests.Load()
+
+		res, err := f.Fetch(ctx, srv.URL+"/players.csv", nil)
+
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrNotModified))
+		assert.Equal(t, before+1, requests.Load())
+	})
+
+	t.Run("unexpected status", func(t *testing.T) {
+		_, err := f.Fetch(ctx, srv.URL+"/404.csv", nil)
+
+		assert.EqualError(t, err, `fetching csv: unexpected status "404 Not Found"`)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := f.Fetch(ctx, srv.URL+"/slow.csv", nil)
+
+		assert.ErrorContains(t, err, "Client.Timeout exceeded")
+	})
+
+	t.Run("non http url", func(t *testing.T) {
+		_, err := f.Fetch(ctx, "file:///etc/passwd", nil)
+
+		assert.EqualError(t, err, fmt.Sprintf("url %q must use http or https", "file:///etc/passwd"))
+	})
+}
+
+func TestSheetCSVURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{
+			name:     "edit link",
+			url:      "https://docs.google.com/spreadsheets/d/abc123/edit#gid=456",
+			expected: "https://docs.google.com/spreadsheets/d/abc123/export?format=csv&gid=456",
+		},
+		{
+			name:     "edit link without gid",
+			url:      "https://docs.google.com/spreadsheets/d/abc123/edit",
+			expected: "https://docs.google.com/spreadsheets/d/abc123/export?format=csv&gid=0",
+		},
+		{
+			name:     "published link",
+			url:      "https://docs.google.com/spreadsheets/d/e/2PACX-abc/pub?output=csv",
+			expected: "https://docs.google.com/spreadsheets/d/e/2PACX-abc/pub?output=csv",
+		},
+		{
+			name:     "other host",
+			url:      "https://example.com/spreadsheets/d/abc123/edit",
+			expected: "https://example.com/spreadsheets/d/abc123/edit",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, SheetCSVURL(tc.url))
+		})
+	}
+}
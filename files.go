@@ -2,17 +2,19 @@ package witcharcana
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/gocarina/gocsv"
 	"github.com/tidwall/pretty"
 )
 
 func open(loc string) (*Clubs, error) {
-	// todo: auto create file if not found
 	dat, err := os.ReadFile(loc)
 	if err != nil {
 		return nil, fmt.Errorf("reading: %w", err)
@@ -20,28 +22,75 @@ func open(loc string) (*Clubs, error) {
 
 	cs := &Clubs{}
 	if len(dat) > 0 {
-		if err = json.Unmarshal(dat, &cs.clubs); err != nil {
+		df, err := decodeDataFile(dat)
+		if err != nil {
 			return nil, fmt.Errorf("unmarshaling: %w", err)
 		}
+
+		cs.clubs = df.Clubs
+		cs.events = df.Events
+		cs.schedules = df.Schedules
+		cs.updatedAt = df.UpdatedAt
 	}
 
 	return cs, nil
 }
 
+// InitDataFile creates an empty data file at loc along with any missing parent directories.
+// ErrAlreadyExists is returned when there's already a file at loc.
+func InitDataFile(loc string) error {
+	if err := os.MkdirAll(filepath.Dir(loc), 0755); err != nil {
+		return fmt.Errorf("creating data dir: %w", err)
+	}
+
+	// reserve loc first so an existing file is never overwritten.
+	f, err := os.OpenFile(loc, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("data file %q %w", loc, ErrAlreadyExists)
+	}
+	if err != nil {
+		return fmt.Errorf("creating: %w", err)
+	}
+	f.Close()
+
+	if _, err := save(loc, &dataFile{Clubs: map[string]*Club{}}); err != nil {
+		os.Remove(loc)
+		return fmt.Errorf("writing: %w", err)
+	}
+
+	return nil
+}
+
 // ReadCSV attempts to read in a csv file from the given location for bulk changes.
-func ReadCSV(loc string) (Players, error) {
-	inputFile, err := os.OpenFile(loc, os.O_RDWR|os.O_CREATE, os.ModePerm)
+func ReadCSV(loc string, opts *CSVOptions) (*CSVResult, error) {
+	inputFile, err := os.Open(loc)
 	if err != nil {
 		return nil, fmt.Errorf("opening: %w", err)
 	}
 	defer inputFile.Close()
 
-	ps := Players{}
-	if err := gocsv.UnmarshalFile(inputFile, &ps); err != nil {
-		return nil, fmt.Errorf("unmarshalling: %w", err)
+	res, err := DecodeCSV(inputFile, opts)
+	if err != nil {
+		return nil, fmt.Errorf("decoding: %w", err)
+	}
+
+	return res, nil
+}
+
+// ReadColumnMapping reads a json object mapping csv headers onto player fields from the given
+// location, ie: {"IGN": "name", "Coords": "location"}.
+func ReadColumnMapping(loc string) (map[string]string, error) {
+	dat, err := os.ReadFile(loc)
+	if err != nil {
+		return nil, fmt.Errorf("reading: %w", err)
 	}
 
-	return ps, nil
+	m := map[string]string{}
+	if err := json.Unmarshal(dat, &m); err != nil {
+		return nil, fmt.Errorf("unmarshaling: %w", err)
+	}
+
+	return m, nil
 }
 
 func (loc *Location) UnmarshalCSV(csv string) error {
@@ -68,17 +117,41 @@ func (loc *Location) UnmarshalCSV(csv string) error {
 	return nil
 }
 
+// MarshalCSV returns the location in the "x:y" form read by UnmarshalCSV.
+func (loc *Location) MarshalCSV() (string, error) {
+	if loc == nil {
+		return "", nil
+	}
+
+	return loc.String(), nil
+}
+
+// Save writes the clubs to loc in the current data file format, backing up the existing file first.
 func Save(loc string, cs map[string]*Club) error {
-	b, err := json.Marshal(cs)
+	_, err := save(loc, &dataFile{Clubs: cs})
+	return err
+}
+
+// save writes the file data to loc in the current version and returns the time recorded as its last
+// update.
+func save(loc string, df *dataFile) (time.Time, error) {
+	df.Version = DataVersion
+	df.UpdatedAt = time.Now().UTC()
+
+	b, err := json.Marshal(df)
 	if err != nil {
-		return fmt.Errorf("marshal: %w", err)
+		return time.Time{}, fmt.Errorf("marshal: %w", err)
 	}
 
 	p := pretty.Pretty(b)
 
-	if err = os.WriteFile(loc, p, 0644); err != nil {
-		return fmt.Errorf("writing: %w", err)
+	if err := backup(loc); err != nil {
+		return time.Time{}, fmt.Errorf("backing up: %w", err)
 	}
 
-	return nil
+	if err = writeFileAtomic(loc, p, 0644); err != nil {
+		return time.Time{}, fmt.Errorf("writing: %w", err)
+	}
+
+	return df.UpdatedAt, nil
 }
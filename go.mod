@@ -4,11 +4,11 @@ go 1.20
 
 require (
 	github.com/bwmarrin/discordgo v0.27.1
-	github.com/gocarina/gocsv v0.0.0-20230513223533-9ddd7fd60602
 	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.8.4
 	github.com/tidwall/pretty v1.2.1
 	go.mongodb.org/mongo-driver v1.11.7
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -29,5 +29,4 @@ require (
 	golang.org/x/sync v0.1.0 // indirect
 	golang.org/x/sys v0.8.0 // indirect
 	golang.org/x/text v0.9.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,65 @@
+package witcharcana
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// lockRetry is how often a held lock is retried while waiting for it.
+const lockRetry = 50 * time.Millisecond
+
+var (
+	// ErrLocked is returned when another process holds the data file lock for longer than the
+	// timeout given.
+	ErrLocked = errors.New("data file is locked by another process")
+	// ErrDataChanged is returned when saving over a data file that was saved by someone else since it
+	// was loaded.
+	ErrDataChanged = errors.New("data file changed since it was loaded")
+
+	errWouldBlock = errors.New("lock held")
+)
+
+// FileLock is an advisory lock held on a data file.
+type FileLock struct {
+	f *os.File
+}
+
+// LockFile acquires an exclusive advisory lock on the data file at loc, waiting up to timeout for
+// other processes to release it. The lock is released by Unlock or when the process exits.
+func LockFile(loc string, timeout time.Duration) (*FileLock, error) {
+	f, err := os.OpenFile(loc+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryLock(f)
+		if err == nil {
+			return &FileLock{f: f}, nil
+		}
+
+		if !errors.Is(err, errWouldBlock) {
+			f.Close()
+			return nil, fmt.Errorf("locking: %w", err)
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, ErrLocked
+		}
+
+		time.Sleep(lockRetry)
+	}
+}
+
+// Unlock releases the lock.
+func (l *FileLock) Unlock() error {
+	if err := unlock(l.f); err != nil {
+		l.f.Close()
+		return fmt.Errorf("unlocking: %w", err)
+	}
+
+	return l.f.Close()
+}
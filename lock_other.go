@@ -0,0 +1,16 @@
+//go:build !unix
+
+package witcharcana
+
+import "os"
+
+// advisory locks aren't supported on this platform, leaving the ErrDataChanged check on save as the
+// only protection against concurrent changes.
+
+func tryLock(f *os.File) error {
+	return nil
+}
+
+func unlock(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,32 @@
+//go:build unix
+
+package witcharcana
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockFile(t *testing.T) {
+	loc := filepath.Join(t.TempDir(), "clubs.json")
+
+	l, err := LockFile(loc, time.Second)
+	assert.NoError(t, err)
+
+	_, err = LockFile(loc, 100*time.Millisecond)
+	assert.True(t, errors.Is(err, ErrLocked))
+
+	// waiting callers get the lock once it's released
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		l.Unlock()
+	}()
+
+	l2, err := LockFile(loc, time.Second)
+	assert.NoError(t, err)
+	assert.NoError(t, l2.Unlock())
+}
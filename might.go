@@ -0,0 +1,75 @@
+package witcharcana
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// mightUnits are the suffixes used for compact might values, largest first.
+var mightUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{suffix: "b", size: 1_000_000_000},
+	{suffix: "m", size: 1_000_000},
+	{suffix: "k", size: 1_000},
+}
+
+// FormatMight returns might in its compact form truncated to one decimal place, as shown in game,
+// ie: 70265122 is 70.2m and 850000 is 850k. Values under a thousand are returned as is.
+func FormatMight(might int64) string {
+	for _, u := range mightUnits {
+		if might < u.size {
+			continue
+		}
+
+		tenths := might / (u.size / 10)
+		if tenths%10 == 0 {
+			return fmt.Sprintf("%d%s", tenths/10, u.suffix)
+		}
+
+		return fmt.Sprintf("%d.%d%s", tenths/10, tenths%10, u.suffix)
+	}
+
+	return strconv.FormatInt(might, 10)
+}
+
+// ParseMight parses might written in full, with thousands separators or in its compact form, ie:
+// 70265122, 70,265,122, 70.2m, 1.5b and 850k.
+func ParseMight(s string) (int64, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	v = strings.NewReplacer(",", "", "_", "").Replace(v)
+	if v == "" {
+		return 0, fmt.Errorf("invalid might %q", s)
+	}
+
+	size := int64(1)
+	for _, u := range mightUnits {
+		if strings.HasSuffix(v, u.suffix) {
+			v, size = strings.TrimSuffix(v, u.suffix), u.size
+			break
+		}
+	}
+
+	if size == 1 {
+		m, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid might %q", s)
+		}
+		return m, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("invalid might %q", s)
+	}
+
+	m := math.Round(f * float64(size))
+	if m >= math.MaxInt64 || m <= math.MinInt64 {
+		return 0, fmt.Errorf("might %q is too large", s)
+	}
+
+	return int64(m), nil
+}
@@ -0,0 +1,48 @@
+package witcharcana
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMight(t *testing.T) {
+	testCases := []struct {
+		in          string
+		expected    int64
+		expectedErr error
+	}{
+		{in: "70265122", expected: 70265122},
+		{in: "70,265,122", expected: 70265122},
+		{in: " 70_265_122 ", expected: 70265122},
+		{in: "70.2m", expected: 70200000},
+		{in: "70.265122M", expected: 70265122},
+		{in: "1.5b", expected: 1500000000},
+		{in: "850k", expected: 850000},
+		{in: "0", expected: 0},
+		{in: "", expectedErr: fmt.Errorf(`invalid might ""`)},
+		{in: "70.2", expectedErr: fmt.Errorf(`invalid might "70.2"`)},
+		{in: "lots", expectedErr: fmt.Errorf(`invalid might "lots"`)},
+		{in: "m", expectedErr: fmt.Errorf(`invalid might "m"`)},
+		{in: "1e30b", expectedErr: fmt.Errorf(`might "1e30b" is too large`)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			actual, err := ParseMight(tc.in)
+
+			assert.Equal(t, tc.expected, actual)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	// compact might round trips to what's shown in game
+	m, err := ParseMight(FormatMight(70265122))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(70200000), m)
+}
@@ -1,8 +1,11 @@
 package witcharcana
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 )
 
 // Players is a collection of players.
@@ -10,14 +13,24 @@ type Players []*Player
 
 // Player represents a player and various data about them.
 type Player struct {
-	Name     string    `json:"name" csv:"name"`
-	Location *Location `json:"location,omitempty" csv:"location"`
-	InHive   bool      `json:"in_hive,omitempty" csv:"in_hive"`
-	Level    int       `json:"level,omitempty" csv:"level,lvl"`
-	Might    int64     `json:"might,omitempty" csv:"might"`
-	Club     string    `json:"club,omitempty" csv:"club"`
+	Name     string    `json:"name"`
+	Location *Location `json:"location,omitempty"`
+	InHive   bool      `json:"in_hive,omitempty"`
+	Level    int       `json:"level,omitempty"`
+	Might    int64     `json:"might,omitempty"`
+	Club     string    `json:"club,omitempty"`
+	Inactive bool      `json:"inactive,omitempty"`
+	Role     Role      `json:"role,omitempty"`
+	Tags     Tags      `json:"tags,omitempty"`
+	Notes    string    `json:"notes,omitempty"`
+	// LastSeen is when the player was last added or updated from any source while UpdatedAt is when
+	// their stats last changed.
+	LastSeen  *time.Time       `json:"last_seen,omitempty"`
+	UpdatedAt *time.Time       `json:"updated_at,omitempty"`
+	Snapshots []*MightSnapshot `json:"might_snapshots,omitempty"`
 }
 
+// NewPlayer returns a pointer to a new player. The player has no location when both x and y are zero.
 func NewPlayer(name, clubName string, level, x, y int) *Player {
 	p := &Player{
 		Name:  name,
@@ -25,7 +38,7 @@ func NewPlayer(name, clubName string, level, x, y int) *Player {
 		Level: level,
 	}
 
-	if x > 0 && y > 0 {
+	if x != 0 || y != 0 {
 		p.Location = &Location{X: x, Y: y}
 	}
 
@@ -33,78 +46,107 @@ func NewPlayer(name, clubName string, level, x, y int) *Player {
 }
 
 // Player returns a given player if found.
-func (cs *Clubs) Player(name string) (*Player, error) {
-	if _, p := player(cs, name); p != nil {
+func (cs *Clubs) Player(ctx context.Context, name string) (*Player, error) {
+	if _, p := player(ctx, cs, name); p != nil {
 		return p, nil
 	}
 
-	return nil, fmt.Errorf("player %q not found", name)
+	return nil, fmt.Errorf("%w: %q", ErrPlayerNotFound, name)
 }
 
-func player(cs *Clubs, name string) (int, *Player) {
-
-	var c Club
-	var p Player
-	for _, club := range cs.clubs {
-		for _, pl := range club.Players {
+func player(ctx context.Context, cs *Clubs, name string) (int, *Player) {
+	for _, c := range cs.clubs {
+		for i, pl := range c.Players {
 			if pl.Name != name {
 				continue
 			}
 
-			p = *pl
-			p.Club = club.Name
-			c = *club
+			if cs.db != nil {
+				fp, err := cs.db.Player(ctx, c.Name, pl.Name)
+				if err != nil {
+					log.Printf("getting player from db: %v", err)
+					return -1, nil
+				}
+				fp.Club = c.Name
+
+				return i, fp
+			}
+
+			p := *pl
+			p.Club = c.Name
 
-			break
+			return i, &p
 		}
 	}
 
-	if &c == nil || &p == nil {
-		return -1, nil
+	return -1, nil
+}
+
+// playerClub returns the named player along with the club they're stored in and their position in
+// it, so edits to the club can be written back.
+func playerClub(ctx context.Context, cs *Clubs, name string) (int, *Player, *Club, error) {
+	_, p := player(ctx, cs, name)
+	if p == nil {
+		return -1, nil, nil, fmt.Errorf("%w: %q", ErrPlayerNotFound, name)
 	}
 
-	if cs.db != nil {
-		fp, err := cs.db.Player(c.Name, p.Name)
-		if err != nil {
-			log.Printf("getting player from db: %v", err)
-			return -1, nil
+	c, err := cs.Club(ctx, p.Club)
+	if err != nil {
+		return -1, nil, nil, fmt.Errorf("getting club: %w", err)
+	}
+	for i, cp := range c.Players {
+		if cp.Name == name {
+			return i, p, c, nil
 		}
-
-		return 0, fp
 	}
 
-	return -1, nil
+	return -1, nil, nil, fmt.Errorf("%w: %q in club %q", ErrPlayerNotFound, name, c.Name)
 }
 
 // CreatePlayer creates a new player.
-func (cs *Clubs) CreatePlayer(clubName string, np *Player) (*Player, error) {
-	fmt.Printf("cs.CreatePlayer: clubName %q, np %q\n", clubName, np.Name)
-	c, err := cs.Club(clubName)
+func (cs *Clubs) CreatePlayer(ctx context.Context, clubName string, np *Player) (*Player, error) {
+	if cs.log {
+		log.Printf("cs.CreatePlayer: clubName %q, np %q\n", clubName, np.Name)
+	}
+	c, err := cs.Club(ctx, clubName)
 	if err != nil {
 		return nil, fmt.Errorf("getting club: %w", err)
 	}
 	if c == nil {
-		return nil, fmt.Errorf("club %q not found", clubName)
+		return nil, fmt.Errorf("%w: %q", ErrClubNotFound, clubName)
 	}
 
-	if err := createPlayer(cs, c, np); err != nil {
+	cs.touch(np, true, true)
+	if err := createPlayer(ctx, cs, c, np); err != nil {
 		return nil, fmt.Errorf("creating player: %w", err)
 	}
 
-	_, p := player(cs, np.Name)
-	fmt.Printf("found player after create: %+v\n", p)
+	_, p := player(ctx, cs, np.Name)
+	if cs.log {
+		log.Printf("found player after create: %+v\n", p)
+	}
 
 	return p, nil
 }
 
-func createPlayer(cs *Clubs, c *Club, np *Player) error {
-	fmt.Printf("checking if player p.Name exists: %q\n", np.Name)
+func createPlayer(ctx context.Context, cs *Clubs, c *Club, np *Player) error {
+	if err := np.Validate(); err != nil {
+		return err
+	}
+
+	if cs.log {
+		log.Printf("checking if player p.Name exists: %q\n", np.Name)
+	}
 	for _, p := range c.Players {
 		if p.Name == np.Name {
-			return fmt.Errorf("player %q already exists in club %q", np.Name, c.Name)
+			return fmt.Errorf("player %q %w in club %q", np.Name, ErrAlreadyExists, c.Name)
 		}
 	}
 
+	if np.Role == RoleLeader && leader(c) >= 0 {
+		return fmt.Errorf("club %q already has a leader: %q", c.Name, c.Leader().Name)
+	}
+
 	if np.Club != "" {
 		np.Club = ""
 	}
@@ -112,7 +154,7 @@ func createPlayer(cs *Clubs, c *Club, np *Player) error {
 	c.Players = append(c.Players, np)
 
 	if cs.db != nil {
-		if err := cs.db.Update(c); err != nil {
+		if err := cs.db.Update(ctx, c); err != nil {
 			return fmt.Errorf("updating db: %w", err)
 		}
 	}
@@ -121,18 +163,18 @@ func createPlayer(cs *Clubs, c *Club, np *Player) error {
 }
 
 // RemovePlayer completely removes a player.
-func (cs *Clubs) RemovePlayer(playerName string) error {
-	if err := removePlayer(cs, playerName); err != nil {
+func (cs *Clubs) RemovePlayer(ctx context.Context, playerName string) error {
+	if err := removePlayer(ctx, cs, playerName); err != nil {
 		return fmt.Errorf("removing player: %w", err)
 	}
 
 	return nil
 }
 
-func removePlayer(clubs *Clubs, playerName string) error {
-	pos, player := player(clubs, playerName)
+func removePlayer(ctx context.Context, clubs *Clubs, playerName string) error {
+	pos, player := player(ctx, clubs, playerName)
 	if player == nil {
-		return fmt.Errorf("player %q does not exist", playerName)
+		return fmt.Errorf("%w: %q", ErrPlayerNotFound, playerName)
 	}
 
 	c := club(clubs.clubs, player.Club)
@@ -141,9 +183,10 @@ func removePlayer(clubs *Clubs, playerName string) error {
 	return nil
 }
 
-// MovePlayer moves a player from one club to another.
-func (cs *Clubs) MovePlayer(playerName, newClubName string) (*Player, error) {
-	player, err := movePlayer(cs, newClubName, playerName)
+// MovePlayer moves a player from one club to another. Roles stay with the club: the player joins as
+// a member and, when they led their old club, its first officer takes over.
+func (cs *Clubs) MovePlayer(ctx context.Context, playerName, newClubName string) (*Player, error) {
+	player, err := movePlayer(ctx, cs, newClubName, playerName, true)
 	if err != nil {
 		return nil, fmt.Errorf("unable to move player %q to %q: %w", playerName, newClubName, err)
 	}
@@ -151,42 +194,62 @@ func (cs *Clubs) MovePlayer(playerName, newClubName string) (*Player, error) {
 	return player, nil
 }
 
-func movePlayer(cs *Clubs, newClubName, playerName string) (*Player, error) {
-	_, p := player(cs, playerName)
+// movePlayer moves the named player to the new club, recording them as seen when seen is true.
+func movePlayer(ctx context.Context, cs *Clubs, newClubName, playerName string, seen bool) (*Player, error) {
+	pos, p := player(ctx, cs, playerName)
 	if p == nil {
-		return nil, fmt.Errorf("player %q does not exist", playerName)
+		return nil, fmt.Errorf("%w: %q", ErrPlayerNotFound, playerName)
 	}
 
-	c, err := cs.Club(newClubName)
+	oc := club(cs.clubs, p.Club)
+
+	c, err := cs.Club(ctx, newClubName)
 	if err != nil {
 		return nil, fmt.Errorf("getting new club: %w", err)
 	}
 
-	if err := createPlayer(cs, c, p); err != nil {
+	// roles stay with the club, so players join their new club as members.
+	mp := *p
+	mp.Role = ""
+	if seen {
+		cs.touch(&mp, false, false)
+	}
+	if err := createPlayer(ctx, cs, c, &mp); err != nil {
 		return nil, fmt.Errorf("creating player in new club: %w", err)
 	}
 
-	if err := removePlayer(cs, p.Name); err != nil {
-		return nil, fmt.Errorf("removing player from old club: %w", err)
+	// remove by position from the old club as the player now exists in both clubs.
+	oc.Players = append(oc.Players[:pos], oc.Players[pos+1:]...)
+	if pc := handOver(oc); pc != nil && cs.log {
+		log.Printf("%s now leads club %q after %s moved to %q\n", pc.Name, oc.Name, p.Name, c.Name)
 	}
 
-	_, p = player(cs, p.Name)
+	_, p = player(ctx, cs, playerName)
 	if p == nil {
-		return nil, fmt.Errorf("could not find player %q after move", p.Name)
+		return nil, fmt.Errorf("could not find player %q after move", playerName)
 	}
 
 	return p, nil
 }
 
-func (cs *Clubs) UpdatePlayer(p *Player) (*Player, error) {
-	n, fp := player(cs, p.Name)
+// UpdatePlayer updates a player's details with the non-zero values provided. Nothing is changed when
+// the updated player would be invalid.
+func (cs *Clubs) UpdatePlayer(ctx context.Context, p *Player) (*Player, error) {
+	n, fp := player(ctx, cs, p.Name)
 	if n < 0 {
-		return nil, fmt.Errorf("player %q not found", p.Name)
+		return nil, fmt.Errorf("%w: %q", ErrPlayerNotFound, p.Name)
 	}
+	// the player is found in their current club regardless of the club given.
+	c := club(cs.clubs, fp.Club)
+
+	changed := len(playerChanges(fp, p)) > 0
+	// updated on a copy as the found player shares its location with the stored one.
+	up := updatePlayer(fp.clone(), p)
+	if err := up.Validate(); err != nil {
+		return nil, err
+	}
+	cs.touch(up, changed, p.Might != 0)
 
-	up := updatePlayer(fp, p)
-
-	c := club(cs.clubs, p.Club)
 	c.Players[n] = up
 
 	return up, nil
@@ -195,56 +258,130 @@ func (cs *Clubs) UpdatePlayer(p *Player) (*Player, error) {
 func updatePlayer(p *Player, up *Player) *Player {
 	p.Club = ""
 
-	if p.Location == nil && (up.Location.X > 0 && up.Location.Y > 0) {
-		l := &Location{X: up.Location.X, Y: up.Location.Y}
-		p.Location = l
-	} else {
-		if p.Location.X != up.Location.X {
-			p.Location.X = up.Location.X
-		}
-		if p.Location.Y != up.Location.Y {
-			p.Location.Y = up.Location.Y
+	if up.Location != nil {
+		if p.Location == nil && (up.Location.X > 0 && up.Location.Y > 0) {
+			l := &Location{X: up.Location.X, Y: up.Location.Y}
+			p.Location = l
+		} else if p.Location != nil {
+			if up.Location.X != 0 && up.Location.X != p.Location.X {
+				p.Location.X = up.Location.X
+			}
+			if up.Location.Y != 0 && up.Location.Y != p.Location.Y {
+				p.Location.Y = up.Location.Y
+			}
 		}
 	}
 	if up.Level != 0 && up.Level != p.Level {
 		p.Level = up.Level
 	}
-	if up.Location.X != 0 && up.Location.X != p.Location.X {
-		p.Location.X = up.Location.X
-	}
-	if up.Location.Y != 0 && up.Location.Y != p.Location.Y {
-		p.Location.Y = up.Location.Y
+	if up.Might != 0 && up.Might != p.Might {
+		p.Might = up.Might
 	}
 	if up.InHive != p.InHive {
 		p.InHive = up.InHive
 	}
+	// tags are replaced when given, as imports list every tag a player has.
+	if up.Tags != nil && !up.Tags.equal(p.Tags) {
+		p.Tags = up.Tags
+	}
 
 	return p
 }
 
-// BulkUpdatePlayers creates/updates players based on data read in from a csv.
-func (cs *Clubs) BulkUpdatePlayers(ps Players) error {
-	return bulkUpdatePlayers(cs, ps)
+// BulkUpdatePlayers creates/updates players based on data read in from a csv and returns the set
+// of changes that were made. When a sync mode is given, the rosters of clubs present in the data are
+// reconciled against it afterwards. Bulk updates are only supported with data files.
+func (cs *Clubs) BulkUpdatePlayers(ctx context.Context, ps Players, mode SyncMode) (*ChangeSet, error) {
+	if cs.db != nil {
+		return nil, errors.New("bulk updates are only supported with data files")
+	}
+
+	// nothing is changed unless every player is valid.
+	var errs []error
+	for _, p := range ps {
+		if err := p.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("bulk update: %w", errors.Join(errs...))
+	}
+
+	// rosters are collected first as creating players clears their club.
+	rs := rosters(ps)
+
+	chs, err := bulkUpdatePlayers(ctx, cs, ps)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syncRosters(ctx, cs, rs, mode, chs); err != nil {
+		return nil, fmt.Errorf("bulk update: %w", err)
+	}
+
+	return chs, nil
+}
+
+// PlanBulkUpdate returns the set of changes BulkUpdatePlayers would make with the given players
+// without modifying any stored data.
+func (cs *Clubs) PlanBulkUpdate(ctx context.Context, ps Players, mode SyncMode) (*ChangeSet, error) {
+	chs, err := cs.clone().BulkUpdatePlayers(ctx, ps.clone(), mode)
+	if err != nil {
+		return nil, fmt.Errorf("planning bulk update: %w", err)
+	}
+
+	return chs, nil
 }
 
-func bulkUpdatePlayers(cs *Clubs, ps Players) error {
-	// todo: add in moving players
+func bulkUpdatePlayers(ctx context.Context, cs *Clubs, ps Players) (*ChangeSet, error) {
+	chs := &ChangeSet{}
+
 	for _, np := range ps {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("bulk update: %w", err)
+		}
+
+		if club(cs.clubs, np.Club) == nil {
+			chs.NewClubs = append(chs.NewClubs, np.Club)
+		}
+
 		c := maybeMakeClub(cs, np.Club)
-		n, p := player(cs, np.Name)
+		n, p := player(ctx, cs, np.Name)
 		if n < 0 {
-			if err := createPlayer(cs, c, np); err != nil {
-				return fmt.Errorf("bulk update: creating player: %w", err)
+			cp := *np
+			cp.Club = c.Name
+			chs.NewPlayers = append(chs.NewPlayers, &cp)
+
+			cs.touch(np, true, true)
+			if err := createPlayer(ctx, cs, c, np); err != nil {
+				return nil, fmt.Errorf("bulk update: creating player: %w", err)
 			}
 			continue
 		}
 
+		if p.Club != c.Name {
+			chs.Moved = append(chs.Moved, &PlayerMove{Name: p.Name, From: p.Club, To: c.Name})
+
+			if _, err := movePlayer(ctx, cs, c.Name, p.Name, true); err != nil {
+				return nil, fmt.Errorf("bulk update: moving player: %w", err)
+			}
+
+			n, p = player(ctx, cs, np.Name)
+		}
+
+		fcs := playerChanges(p, np)
+
 		up := updatePlayer(p, np)
 		if up == nil {
-			return fmt.Errorf("bulk update: failed to update player: %q", p.Name)
+			return nil, fmt.Errorf("bulk update: failed to update player: %q", p.Name)
 		}
+		cs.touch(up, len(fcs) > 0, np.Might != 0)
 		c.Players[n] = up
+
+		if len(fcs) > 0 {
+			chs.Updated = append(chs.Updated, &PlayerChange{Name: up.Name, Club: c.Name, Fields: fcs})
+		}
 	}
 
-	return nil
+	return chs, nil
 }
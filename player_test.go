@@ -1,6 +1,8 @@
 package witcharcana
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -23,7 +25,7 @@ func TestMethodPlayer(t *testing.T) {
 					"CNT": {Name: "CNT", Players: []*Player{{Name: "mxygem"}}},
 				},
 			},
-			expectedErr: fmt.Errorf(`player "Hoeb" not found`),
+			expectedErr: fmt.Errorf(`player not found: "Hoeb"`),
 		},
 		{
 			name:       "player found",
@@ -40,7 +42,7 @@ func TestMethodPlayer(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			actual, err := tc.clubs.Player(tc.playerName)
+			actual, err := tc.clubs.Player(context.Background(), tc.playerName)
 
 			assert.Equal(t, tc.expected, actual)
 			if tc.expectedErr != nil {
@@ -75,7 +77,7 @@ func TestPlayer(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			pos, actual := player(tc.clubs, tc.playerName)
+			pos, actual := player(context.Background(), &tc.clubs, tc.playerName)
 
 			assert.Equal(t, tc.expected, actual)
 			assert.Equal(t, tc.expectedPos, pos)
@@ -102,7 +104,7 @@ func TestCreatePlayer(t *testing.T) {
 					"SP":  {Name: "SP", Players: []*Player{{Name: "Quinoa"}, {Name: "Jasmin"}}},
 				},
 			},
-			expectedErr: fmt.Errorf(`club "DYR" not found`),
+			expectedErr: fmt.Errorf(`getting club: club not found: "DYR"`),
 		},
 		{
 			name:     "player exists",
@@ -130,7 +132,7 @@ func TestCreatePlayer(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			actual, err := tc.clubs.CreatePlayer(tc.clubName, tc.player)
+			actual, err := tc.clubs.CreatePlayer(context.Background(), tc.clubName, tc.player)
 
 			assert.Equal(t, tc.expected, actual)
 			if tc.expectedErr != nil {
@@ -163,7 +165,7 @@ func TestRemovePlayer(t *testing.T) {
 					"DYR": {Name: "DYR", Players: []*Player{{Name: "RubyBlack"}, {Name: "Spooffy"}}},
 				},
 			},
-			expectedErr: fmt.Errorf(`removing player: player "Wishy" does not exist`),
+			expectedErr: fmt.Errorf(`removing player: player not found: "Wishy"`),
 		},
 		{
 			name:       "successfully removed from beginning",
@@ -222,7 +224,7 @@ func TestRemovePlayer(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := tc.clubs.RemovePlayer(tc.playerName)
+			err := tc.clubs.RemovePlayer(context.Background(), tc.playerName)
 
 			assert.Equal(t, tc.expected, tc.clubs)
 			if tc.expectedErr != nil {
@@ -260,7 +262,7 @@ func TestMovePlayer(t *testing.T) {
 					"SP":  {Name: "SP", Players: []*Player{{Name: "Quinoa"}, {Name: "Jasmin"}}},
 				},
 			},
-			expectedErr: fmt.Errorf(`unable to move player "treees" to "SP": player "treees" does not exist`),
+			expectedErr: fmt.Errorf(`unable to move player "treees" to "SP": player not found: "treees"`),
 		},
 		{
 			name:        "new club not found",
@@ -276,7 +278,7 @@ func TestMovePlayer(t *testing.T) {
 					"CNT": {Name: "CNT", Players: []*Player{{Name: "mxygem"}, {Name: "Hoeb"}}},
 				},
 			},
-			expectedErr: fmt.Errorf(`unable to move player "mxygem" to "SP": getting new club: no club "SP" found`),
+			expectedErr: fmt.Errorf(`unable to move player "mxygem" to "SP": getting new club: club not found: "SP"`),
 		},
 		{
 			name:        "player already exists in new club?",
@@ -318,7 +320,7 @@ func TestMovePlayer(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			actual, err := tc.clubs.MovePlayer(tc.playerName, tc.newClubName)
+			actual, err := tc.clubs.MovePlayer(context.Background(), tc.playerName, tc.newClubName)
 
 			assert.Equal(t, tc.expected, actual)
 			assert.Equal(t, tc.expectedClubs, tc.clubs)
@@ -425,12 +427,34 @@ func TestCsBulkUpdatePlayers(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "players moved between clubs",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"CNT": {Name: "CNT", Players: []*Player{{Name: "Hoeb"}, {Name: "mxygem", Level: 18}, {Name: "M4rs"}}},
+					"SP":  {Name: "SP", Players: []*Player{{Name: "Quinoa"}}},
+				},
+			},
+			players: []*Player{
+				{Name: "mxygem", Level: 19, Club: "SP"},
+				{Name: "Hoeb", Club: "MID"},
+			},
+			expected: Clubs{
+				clubs: map[string]*Club{
+					"CNT": {Name: "CNT", Players: []*Player{{Name: "M4rs"}}},
+					"SP": {Name: "SP", Players: []*Player{
+						{Name: "Quinoa"},
+						{Name: "mxygem", Level: 19},
+					}},
+					"MID": {Name: "MID", Players: []*Player{{Name: "Hoeb"}}},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			fmt.Printf("epl: %d\n", len(tc.expected.clubs["CNT"].Players))
-			err := tc.clubs.BulkUpdatePlayers(tc.players)
+			_, err := tc.clubs.BulkUpdatePlayers(context.Background(), tc.players, SyncNone)
 
 			assert.Equal(t, tc.expected, tc.clubs)
 			if tc.expectedErr != nil {
@@ -466,7 +490,7 @@ func TestCsUpdatePlayer(t *testing.T) {
 				Club:  "CNT",
 				Level: 19,
 			},
-			expectedErr: fmt.Errorf(`player "mxygem" not found`),
+			expectedErr: fmt.Errorf(`player not found: "mxygem"`),
 		},
 		{
 			name: "player found",
@@ -496,11 +520,33 @@ func TestCsUpdatePlayer(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "invalid update leaves stored player",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"CNT": {Name: "CNT", Players: []*Player{
+						{Name: "M4rs", Level: 15, Location: &Location{X: 300, Y: 5}},
+					}},
+				},
+			},
+			player: &Player{
+				Name:     "M4rs",
+				Location: &Location{X: 5000},
+			},
+			expectedClubs: Clubs{
+				clubs: map[string]*Club{
+					"CNT": {Name: "CNT", Players: []*Player{
+						{Name: "M4rs", Level: 15, Location: &Location{X: 300, Y: 5}},
+					}},
+				},
+			},
+			expectedErr: fmt.Errorf(`invalid player "M4rs": location.x must be between 1 and 1000. got 5000`),
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			actual, err := tc.clubs.UpdatePlayer(tc.player)
+			actual, err := tc.clubs.UpdatePlayer(context.Background(), tc.player)
 
 			assert.Equal(t, tc.expected, actual)
 			if tc.expectedClubs.clubs != nil {
@@ -548,3 +594,22 @@ func TestUpdatePlayer(t *testing.T) {
 		})
 	}
 }
+
+func TestBulkUpdatePlayersCancelled(t *testing.T) {
+	cs := &Clubs{clubs: map[string]*Club{"AZA": {Name: "AZA"}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := cs.BulkUpdatePlayers(ctx, Players{{Name: "Fayeee", Club: "AZA"}}, SyncNone)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Empty(t, cs.clubs["AZA"].Players)
+}
+
+func TestBulkUpdatePlayersDB(t *testing.T) {
+	cs := NewClubs(NewDB(&DBConfig{}), false)
+
+	_, err := cs.BulkUpdatePlayers(context.Background(), Players{{Name: "Fayeee", Club: "AZA"}}, SyncNone)
+	assert.EqualError(t, err, "bulk updates are only supported with data files")
+	assert.Empty(t, cs.All(), "nothing is created in memory")
+}
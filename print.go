@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"text/template"
 
 	"github.com/tidwall/pretty"
 )
@@ -32,14 +31,10 @@ func Print(o any) error {
 	return nil
 }
 
+// PrettyDiscord renders all clubs with the built in clubs template.
 func PrettyDiscord(w io.Writer, data *Clubs) error {
-	tmpl, err := template.New("").ParseFiles("./templates/discord.tmpl")
-	if err != nil {
-		return fmt.Errorf("initiating template: %w", err)
-	}
-
-	if err := tmpl.ExecuteTemplate(w, "discord.tmpl", data.clubs); err != nil {
-		return fmt.Errorf("executing template: %w", err)
+	if err := NewTemplates("").Execute(w, "clubs", data.clubs); err != nil {
+		return fmt.Errorf("rendering clubs: %w", err)
 	}
 
 	return nil
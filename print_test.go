@@ -2,7 +2,6 @@ package witcharcana
 
 import (
 	"bytes"
-	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,14 +14,16 @@ func TestPrettyDiscord(t *testing.T) {
 		expected    string
 		expectedErr error
 	}{
-		// 		{
-		// 			name: "no data",
-		// 			expected: `=====
-		// no data
-		// =====`,
-		// 		},
 		{
 			name: "no data",
+			data: &Clubs{clubs: map[string]*Club{}},
+			expected: `==========
+No club data found!
+==========
+`,
+		},
+		{
+			name: "clubs",
 			data: &Clubs{
 				clubs: map[string]*Club{
 					"404": {Name: "404", Location: &Location{X: 123, Y: 456}, Players: Players{
@@ -39,27 +40,28 @@ func TestPrettyDiscord(t *testing.T) {
 			},
 			expected: `==========
 Clubs:
-	Name: 404
-		Loc: 123, 456
-		Players:
-			Name: DireVoidCat
-			Name: LoverOnyx
-				Loc: 123, 457
-				In Hive: true
-			Name: AnotherPerson
-				Loc: 789, 567
-				In Hive: true
-			
-	Name: AZA
-		Players:
-			Name: Fayee
-				Loc: 303, 733
-				Level: 18
-				Might: 70265122
-				InHive: False
-			Name: Richard
-
-			==========`,
+    Name: 404
+    Loc: 123, 456
+    Players:
+        Name: DireVoidCat
+            Level: 15
+            Might: 51.8m
+        Name: LoverOnyx
+            Loc: 123, 457
+            In Hive: true
+        Name: AnotherPerson
+            Loc: 789, 567
+            In Hive: true
+    Name: AZA
+    Players:
+        Name: Fayeee
+            Loc: 303, 733
+            Level: 18
+            Might: 70.2m
+            In Hive: false
+        Name: Richard
+==========
+`,
 		},
 	}
 	for _, tc := range testCases {
@@ -68,8 +70,6 @@ Clubs:
 
 			err := PrettyDiscord(&b, tc.data)
 
-			fmt.Println(b.String())
-
 			assert.Equal(t, tc.expectedErr, err)
 			assert.Equal(t, tc.expected, b.String())
 		})
@@ -0,0 +1,202 @@
+package witcharcana
+
+import (
+	"context"
+	"fmt"
+)
+
+// Recruitment is whether a club is taking on new members.
+type Recruitment string
+
+const (
+	// RecruitmentOpen clubs take anyone who applies.
+	RecruitmentOpen Recruitment = "open"
+	// RecruitmentInvite clubs only take players they've invited.
+	RecruitmentInvite Recruitment = "invite"
+	// RecruitmentClosed clubs aren't taking new members.
+	RecruitmentClosed Recruitment = "closed"
+)
+
+var recruitments = []Recruitment{RecruitmentOpen, RecruitmentInvite, RecruitmentClosed}
+
+// ParseRecruitment returns the recruitment state named by s.
+func ParseRecruitment(s string) (Recruitment, error) {
+	for _, r := range recruitments {
+		if string(r) == s {
+			return r, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown recruitment state %q. options: %v", s, recruitments)
+}
+
+// Prospects is a collection of prospects.
+type Prospects []*Prospect
+
+// Prospect is a player a club is recruiting who isn't a member yet.
+type Prospect struct {
+	Name  string `json:"name"`
+	Level int    `json:"level,omitempty"`
+	Might int64  `json:"might,omitempty"`
+	// Contact holds notes on how to reach the player and how recruiting them is going.
+	Contact string `json:"contact,omitempty"`
+}
+
+// Validate checks the prospect's name, level and might.
+func (pr *Prospect) Validate() error {
+	return validationError("prospect", pr.Name, pr.validate(""))
+}
+
+func (pr *Prospect) validate(prefix string) []*FieldError {
+	return pr.player().validate(prefix)
+}
+
+// player returns the prospect as a new player.
+func (pr *Prospect) player() *Player {
+	return &Player{Name: pr.Name, Level: pr.Level, Might: pr.Might}
+}
+
+// Excess returns the number of players over the club's capacity. Clubs without a capacity are never
+// over it.
+func (c *Club) Excess() int {
+	if c.Capacity <= 0 || len(c.Players) <= c.Capacity {
+		return 0
+	}
+
+	return len(c.Players) - c.Capacity
+}
+
+// CapacityWarning describes how far the club is over its capacity, or returns an empty string when
+// it isn't.
+func (c *Club) CapacityWarning() string {
+	n := c.Excess()
+	if n == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("club %q has %d players, %d over its capacity of %d", c.Name, len(c.Players), n, c.Capacity)
+}
+
+// Prospects returns the prospects of the named club.
+func (cs *Clubs) Prospects(ctx context.Context, clubName string) (Prospects, error) {
+	c, err := cs.Club(ctx, clubName)
+	if err != nil {
+		return nil, fmt.Errorf("getting club: %w", err)
+	}
+
+	return c.Prospects, nil
+}
+
+// AddProspect adds a prospect to the named club's recruiting list.
+func (cs *Clubs) AddProspect(ctx context.Context, clubName string, pr *Prospect) error {
+	if err := pr.Validate(); err != nil {
+		return err
+	}
+
+	c, err := cs.Club(ctx, clubName)
+	if err != nil {
+		return fmt.Errorf("getting club: %w", err)
+	}
+
+	if prospect(c, pr.Name) >= 0 {
+		return fmt.Errorf("prospect %q %w in club %q", pr.Name, ErrAlreadyExists, c.Name)
+	}
+	for _, p := range c.Players {
+		if p.Name == pr.Name {
+			return fmt.Errorf("player %q %w in club %q", pr.Name, ErrAlreadyExists, c.Name)
+		}
+	}
+
+	c.Prospects = append(c.Prospects, pr)
+
+	return cs.updateProspects(ctx, c)
+}
+
+// RemoveProspect removes a prospect from the named club's recruiting list.
+func (cs *Clubs) RemoveProspect(ctx context.Context, clubName, name string) error {
+	c, err := cs.Club(ctx, clubName)
+	if err != nil {
+		return fmt.Errorf("getting club: %w", err)
+	}
+
+	if err := removeProspect(c, name); err != nil {
+		return err
+	}
+
+	return cs.updateProspects(ctx, c)
+}
+
+// PromoteProspect makes a prospect a player of the club they were recruited to, removing them from
+// its recruiting list. The prospect is kept when the player can't be created or already plays for
+// another club. Check the club's
+// CapacityWarning afterwards as promoting doesn't stop at the club's capacity.
+func (cs *Clubs) PromoteProspect(ctx context.Context, clubName, name string) (*Player, error) {
+	c, err := cs.Club(ctx, clubName)
+	if err != nil {
+		return nil, fmt.Errorf("getting club: %w", err)
+	}
+
+	i := prospect(c, name)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %q in club %q", ErrProspectNotFound, name, clubName)
+	}
+	// players are only in one club, so a prospect already playing elsewhere has to be moved instead.
+	if p, err := cs.Player(ctx, name); err == nil {
+		return nil, fmt.Errorf("promoting prospect: player %q %w in club %q", name, ErrAlreadyExists, p.Club)
+	}
+
+	p, err := cs.CreatePlayer(ctx, clubName, c.Prospects[i].player())
+	if err != nil {
+		return nil, fmt.Errorf("promoting prospect: %w", err)
+	}
+
+	// the club is fetched again as creating the player may have replaced it when stored in a database.
+	c, err = cs.Club(ctx, clubName)
+	if err != nil {
+		return nil, fmt.Errorf("getting club: %w", err)
+	}
+	if err := removeProspect(c, name); err != nil {
+		return nil, err
+	}
+	if err := cs.updateProspects(ctx, c); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// updateProspects writes the club's changed prospects to the database, if used.
+func (cs *Clubs) updateProspects(ctx context.Context, c *Club) error {
+	if cs.db == nil {
+		return nil
+	}
+
+	if err := cs.db.Update(ctx, c); err != nil {
+		return fmt.Errorf("updating db: %w", err)
+	}
+
+	return nil
+}
+
+func removeProspect(c *Club, name string) error {
+	i := prospect(c, name)
+	if i < 0 {
+		return fmt.Errorf("%w: %q in club %q", ErrProspectNotFound, name, c.Name)
+	}
+
+	c.Prospects = append(c.Prospects[:i], c.Prospects[i+1:]...)
+
+	return nil
+}
+
+// prospect returns the position of the named prospect in the club's recruiting list or -1 when
+// they're not on it.
+func prospect(c *Club, name string) int {
+	for i, pr := range c.Prospects {
+		if pr.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,225 @@
+package witcharcana
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRecruitment(t *testing.T) {
+	r, err := ParseRecruitment("invite")
+	assert.NoError(t, err)
+	assert.Equal(t, RecruitmentInvite, r)
+
+	_, err = ParseRecruitment("maybe")
+	assert.EqualError(t, err, `unknown recruitment state "maybe". options: [open invite closed]`)
+}
+
+func TestCsAddProspect(t *testing.T) {
+	testCases := []struct {
+		name              string
+		clubs             Clubs
+		clubName          string
+		prospect          *Prospect
+		expectedProspects Prospects
+		expectedErr       error
+	}{
+		{
+			name: "added",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Prospects: Prospects{{Name: "Quinoa", Level: 16, Might: 850000, Contact: "dm'd on discord"}}},
+				},
+			},
+			clubName: "AZA",
+			prospect: &Prospect{Name: "mxygem", Level: 18},
+			expectedProspects: Prospects{
+				{Name: "Quinoa", Level: 16, Might: 850000, Contact: "dm'd on discord"},
+				{Name: "mxygem", Level: 18},
+			},
+		},
+		{
+			name: "invalid",
+			clubs: Clubs{
+				clubs: map[string]*Club{"AZA": {Name: "AZA"}},
+			},
+			clubName:    "AZA",
+			prospect:    &Prospect{Name: "mxygem", Level: 101},
+			expectedErr: fmt.Errorf(`invalid prospect "mxygem": level must be between 0 and 100. got 101`),
+		},
+		{
+			name: "club not found",
+			clubs: Clubs{
+				clubs: map[string]*Club{"AZA": {Name: "AZA"}},
+			},
+			clubName:    "404",
+			prospect:    &Prospect{Name: "mxygem"},
+			expectedErr: fmt.Errorf(`getting club: club not found: "404"`),
+		},
+		{
+			name: "already a prospect",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Prospects: Prospects{{Name: "Quinoa"}}},
+				},
+			},
+			clubName:    "AZA",
+			prospect:    &Prospect{Name: "Quinoa"},
+			expectedErr: fmt.Errorf(`prospect "Quinoa" already exists in club "AZA"`),
+		},
+		{
+			name: "already a member",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee"}}},
+				},
+			},
+			clubName:    "AZA",
+			prospect:    &Prospect{Name: "Fayeee"},
+			expectedErr: fmt.Errorf(`player "Fayeee" already exists in club "AZA"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.clubs.AddProspect(context.Background(), tc.clubName, tc.prospect)
+
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedProspects, tc.clubs.clubs[tc.clubName].Prospects)
+		})
+	}
+}
+
+func TestCsRemoveProspect(t *testing.T) {
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"AZA": {Name: "AZA", Prospects: Prospects{{Name: "Quinoa"}}},
+		},
+	}
+
+	assert.NoError(t, cs.RemoveProspect(context.Background(), "AZA", "Quinoa"))
+	assert.Empty(t, cs.clubs["AZA"].Prospects)
+
+	err := cs.RemoveProspect(context.Background(), "AZA", "Quinoa")
+	assert.EqualError(t, err, `prospect not found: "Quinoa" in club "AZA"`)
+	assert.True(t, errors.Is(err, ErrProspectNotFound))
+}
+
+func TestCsPromoteProspect(t *testing.T) {
+	testCases := []struct {
+		name            string
+		clubs           Clubs
+		clubName        string
+		prospect        string
+		expected        *Player
+		expectedWarning string
+		expectedErr     error
+	}{
+		{
+			name: "promoted over capacity",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Capacity: 2,
+						Players:   Players{{Name: "Fayeee"}, {Name: "Richard"}},
+						Prospects: Prospects{{Name: "Quinoa", Level: 16, Might: 850000, Contact: "dm'd on discord"}},
+					},
+				},
+			},
+			clubName:        "AZA",
+			prospect:        "Quinoa",
+			expected:        &Player{Name: "Quinoa", Club: "AZA", Level: 16, Might: 850000},
+			expectedWarning: `club "AZA" has 3 players, 1 over its capacity of 2`,
+		},
+		{
+			name: "prospect not found",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Prospects: Prospects{{Name: "Quinoa"}}},
+					"SP":  {Name: "SP", Players: Players{{Name: "Hoeb"}}},
+				},
+			},
+			clubName:    "SP",
+			prospect:    "Quinoa",
+			expectedErr: fmt.Errorf(`prospect not found: "Quinoa" in club "SP"`),
+		},
+		{
+			name: "club not found",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Prospects: Prospects{{Name: "Quinoa"}}},
+				},
+			},
+			clubName:    "404",
+			prospect:    "Quinoa",
+			expectedErr: fmt.Errorf(`getting club: club not found: "404"`),
+		},
+		{
+			name: "player in another club",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Prospects: Prospects{{Name: "Hoeb"}}},
+					"SP":  {Name: "SP", Players: Players{{Name: "Hoeb"}}},
+				},
+			},
+			clubName:    "AZA",
+			prospect:    "Hoeb",
+			expectedErr: fmt.Errorf(`promoting prospect: player "Hoeb" already exists in club "SP"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.clubs.PromoteProspect(context.Background(), tc.clubName, tc.prospect)
+
+			assert.Equal(t, tc.expected, actual)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Empty(t, tc.clubs.clubs[tc.clubName].Prospects)
+			assert.Equal(t, tc.expectedWarning, tc.clubs.clubs[tc.clubName].CapacityWarning())
+		})
+	}
+
+	// prospects who can't become players are kept
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee"}}, Prospects: Prospects{{Name: "Fayeee"}}},
+		},
+	}
+	_, err := cs.PromoteProspect(context.Background(), "AZA", "Fayeee")
+	assert.True(t, errors.Is(err, ErrAlreadyExists))
+	assert.Len(t, cs.clubs["AZA"].Prospects, 1)
+}
+
+func TestClubCapacityWarning(t *testing.T) {
+	assert.Empty(t, (&Club{Name: "SP", Players: Players{{Name: "Hoeb"}}}).CapacityWarning())
+	assert.Empty(t, (&Club{Name: "SP", Capacity: 1, Players: Players{{Name: "Hoeb"}}}).CapacityWarning())
+	assert.Equal(t, 1, (&Club{Name: "SP", Capacity: 1, Players: Players{{Name: "Hoeb"}, {Name: "M4rs"}}}).Excess())
+}
+
+func TestProspectsTemplate(t *testing.T) {
+	c := &Club{Name: "AZA", Capacity: 2, Recruitment: RecruitmentOpen,
+		Players:   Players{{Name: "Fayeee"}, {Name: "Richard"}},
+		Prospects: Prospects{{Name: "Quinoa", Level: 16, Might: 850000, Contact: "dm'd on discord"}},
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, NewTemplates("").Render(&b, c.Prospects))
+	assert.Equal(t, "Prospects:\n  Quinoa                16   850k  dm'd on discord\n", b.String())
+
+	b.Reset()
+	assert.NoError(t, NewTemplates("").Execute(&b, "roster", c))
+	assert.Equal(t, "AZA - 2/2 players, recruitment open\n"+
+		"  Fayeee                 -      0\n"+
+		"  Richard                -      0\n\n", b.String())
+}
@@ -0,0 +1,188 @@
+package witcharcana
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Role is a player's position within their club. Players without a role are members. Roles are
+// changed with SetRole, PromotePlayer and DemotePlayer so clubs keep a single leader.
+type Role string
+
+const (
+	// RoleLeader runs the club. Clubs have at most one.
+	RoleLeader Role = "leader"
+	// RoleOfficer helps the leader run the club.
+	RoleOfficer Role = "officer"
+	// RoleMember is every other player, stored as an empty role.
+	RoleMember Role = "member"
+)
+
+// ParseRole returns the role named by s. Names other than leader, officer and member are custom
+// roles, ie: recruiter, which rank between officers and members.
+func ParseRole(s string) (Role, error) {
+	r := Role(strings.ToLower(strings.TrimSpace(s)))
+	if r == "" {
+		return "", &FieldError{Field: "role", Reason: "required"}
+	}
+	if fe := validateRole("role", r); fe != nil {
+		return "", fe
+	}
+	if r == RoleMember {
+		return "", nil
+	}
+
+	return r, nil
+}
+
+// String returns the role's name, which is member for players without one.
+func (r Role) String() string {
+	if r == "" {
+		return string(RoleMember)
+	}
+
+	return string(r)
+}
+
+// rank orders roles from the leader down to members.
+func (r Role) rank() int {
+	switch r {
+	case RoleLeader:
+		return 0
+	case RoleOfficer:
+		return 1
+	case "", RoleMember:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// Leader returns the club's leader or nil when it doesn't have one.
+func (c *Club) Leader() *Player {
+	if i := leader(c); i >= 0 {
+		return c.Players[i]
+	}
+
+	return nil
+}
+
+// leader returns the position of the club's leader or -1 when it doesn't have one.
+func leader(c *Club) int {
+	for i, p := range c.Players {
+		if p.Role == RoleLeader {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// SetRole gives the named player a role and returns the changes made. Making a player the leader
+// hands leadership over from the club's current leader, who becomes an officer.
+func (cs *Clubs) SetRole(ctx context.Context, name string, r Role) (*ChangeSet, error) {
+	if r == RoleMember {
+		r = ""
+	}
+	if fe := validateRole("role", r); fe != nil {
+		return nil, validationError("player", name, []*FieldError{fe})
+	}
+
+	n, p, c, err := playerClub(ctx, cs, name)
+	if err != nil {
+		return nil, err
+	}
+
+	chs := &ChangeSet{}
+	if p.Role == r {
+		return chs, nil
+	}
+
+	if l := leader(c); r == RoleLeader && l >= 0 {
+		chs.Updated = append(chs.Updated, setRole(c, l, RoleOfficer))
+	}
+	chs.Updated = append(chs.Updated, setRole(c, n, r))
+	cs.touch(c.Players[n], false, false)
+
+	if cs.db != nil {
+		if err := cs.db.Update(ctx, c); err != nil {
+			return nil, fmt.Errorf("updating db: %w", err)
+		}
+	}
+
+	return chs, nil
+}
+
+// PromotePlayer raises the named player one role: members and custom roles become officers and
+// officers become the leader, taking over from the current one.
+func (cs *Clubs) PromotePlayer(ctx context.Context, name string) (*ChangeSet, error) {
+	p, err := cs.Player(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch p.Role {
+	case RoleLeader:
+		return nil, fmt.Errorf("player %q already leads club %q", name, p.Club)
+	case RoleOfficer:
+		return cs.SetRole(ctx, name, RoleLeader)
+	default:
+		return cs.SetRole(ctx, name, RoleOfficer)
+	}
+}
+
+// DemotePlayer lowers the named player one role: the leader becomes an officer, leaving the club
+// without a leader, and officers and custom roles become members.
+func (cs *Clubs) DemotePlayer(ctx context.Context, name string) (*ChangeSet, error) {
+	p, err := cs.Player(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch p.Role {
+	case "":
+		return nil, fmt.Errorf("player %q is already a member of club %q", name, p.Club)
+	case RoleLeader:
+		return cs.SetRole(ctx, name, RoleOfficer)
+	default:
+		return cs.SetRole(ctx, name, "")
+	}
+}
+
+// setRole changes the role of the club's player at position n and returns the change.
+func setRole(c *Club, n int, r Role) *PlayerChange {
+	p := *c.Players[n]
+	pc := &PlayerChange{Name: p.Name, Club: c.Name, Fields: []FieldChange{
+		{Field: "role", Old: p.Role.String(), New: r.String()},
+	}}
+
+	p.Role = r
+	c.Players[n] = &p
+
+	return pc
+}
+
+// handOver passes leadership of a club that lost its leader to its first officer, if it has one.
+func handOver(c *Club) *PlayerChange {
+	if leader(c) >= 0 {
+		return nil
+	}
+
+	for i, p := range c.Players {
+		if p.Role == RoleOfficer {
+			return setRole(c, i, RoleLeader)
+		}
+	}
+
+	return nil
+}
+
+// validateRole checks a stored role. Custom roles follow the same rules as tags.
+func validateRole(field string, r Role) *FieldError {
+	if r == "" {
+		return nil
+	}
+
+	return validateLabel(field, "", string(r))
+}
@@ -0,0 +1,198 @@
+package witcharcana
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRole(t *testing.T) {
+	testCases := []struct {
+		in          string
+		expected    Role
+		expectedErr error
+	}{
+		{in: "Leader", expected: RoleLeader},
+		{in: "officer", expected: RoleOfficer},
+		{in: "member", expected: ""},
+		{in: "recruiter", expected: "recruiter"},
+		{in: " ", expectedErr: fmt.Errorf("role required")},
+		{in: "war:chief", expectedErr: fmt.Errorf(`role "war:chief" cannot contain ':'`)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			actual, err := ParseRole(tc.in)
+
+			assert.Equal(t, tc.expected, actual)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.Equal(t, "member", Role("").String())
+}
+
+func TestCsSetRole(t *testing.T) {
+	testCases := []struct {
+		name        string
+		clubs       Clubs
+		player      string
+		role        Role
+		expected    []*PlayerChange
+		expectedErr error
+	}{
+		{
+			name: "leadership handed over",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee", Role: RoleLeader}, {Name: "LoverOnyx"}}},
+				},
+			},
+			player: "LoverOnyx",
+			role:   RoleLeader,
+			expected: []*PlayerChange{
+				{Name: "Fayeee", Club: "AZA", Fields: []FieldChange{{Field: "role", Old: "leader", New: "officer"}}},
+				{Name: "LoverOnyx", Club: "AZA", Fields: []FieldChange{{Field: "role", Old: "member", New: "leader"}}},
+			},
+		},
+		{
+			name: "member",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{{Name: "Quinoa", Role: "recruiter"}}},
+				},
+			},
+			player: "Quinoa",
+			role:   RoleMember,
+			expected: []*PlayerChange{
+				{Name: "Quinoa", Club: "AZA", Fields: []FieldChange{{Field: "role", Old: "recruiter", New: "member"}}},
+			},
+		},
+		{
+			name: "unchanged",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee", Role: RoleLeader}}},
+				},
+			},
+			player: "Fayeee",
+			role:   RoleLeader,
+		},
+		{
+			name: "invalid",
+			clubs: Clubs{
+				clubs: map[string]*Club{"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee"}}}},
+			},
+			player:      "Fayeee",
+			role:        "War Chief",
+			expectedErr: fmt.Errorf(`invalid player "Fayeee": role "War Chief" cannot contain 'W'`),
+		},
+		{
+			name: "player not found",
+			clubs: Clubs{
+				clubs: map[string]*Club{"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee"}}}},
+			},
+			player:      "M4rs",
+			role:        RoleOfficer,
+			expectedErr: fmt.Errorf(`player not found: "M4rs"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.clubs.SetRole(context.Background(), tc.player, tc.role)
+
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual.Updated)
+			assert.NoError(t, tc.clubs.clubs["AZA"].Validate())
+		})
+	}
+}
+
+func TestCsPromoteDemotePlayer(t *testing.T) {
+	ctx := context.Background()
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee", Role: RoleLeader}, {Name: "Quinoa", Role: "recruiter"}}},
+		},
+	}
+
+	_, err := cs.PromotePlayer(ctx, "Quinoa")
+	assert.NoError(t, err)
+	_, err = cs.PromotePlayer(ctx, "Quinoa")
+	assert.NoError(t, err)
+	assert.Equal(t, RoleLeader, cs.clubs["AZA"].Leader().Role)
+	assert.Equal(t, "Quinoa", cs.clubs["AZA"].Leader().Name)
+	assert.Equal(t, RoleOfficer, cs.clubs["AZA"].Players[0].Role)
+
+	_, err = cs.PromotePlayer(ctx, "Quinoa")
+	assert.EqualError(t, err, `player "Quinoa" already leads club "AZA"`)
+
+	_, err = cs.DemotePlayer(ctx, "Quinoa")
+	assert.NoError(t, err)
+	assert.Nil(t, cs.clubs["AZA"].Leader())
+	_, err = cs.DemotePlayer(ctx, "Quinoa")
+	assert.NoError(t, err)
+	_, err = cs.DemotePlayer(ctx, "Quinoa")
+	assert.EqualError(t, err, `player "Quinoa" is already a member of club "AZA"`)
+
+	_, err = cs.DemotePlayer(ctx, "M4rs")
+	assert.True(t, errors.Is(err, ErrPlayerNotFound))
+}
+
+func TestMovePlayerRoles(t *testing.T) {
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"AZA": {Name: "AZA", Players: Players{
+				{Name: "Fayeee", Role: RoleLeader},
+				{Name: "LoverOnyx"},
+				{Name: "Richard", Role: RoleOfficer},
+			}},
+			"SP": {Name: "SP", Players: Players{{Name: "Hoeb", Role: RoleLeader}}},
+		},
+	}
+
+	p, err := cs.MovePlayer(context.Background(), "Fayeee", "SP")
+	assert.NoError(t, err)
+	assert.Equal(t, Role(""), p.Role, "players join their new club as members")
+	assert.Equal(t, "Hoeb", cs.clubs["SP"].Leader().Name)
+	assert.Equal(t, "Richard", cs.clubs["AZA"].Leader().Name, "the first officer takes over")
+
+	_, err = cs.CreatePlayer(context.Background(), "SP", &Player{Name: "M4rs", Role: RoleLeader})
+	assert.EqualError(t, err, `creating player: club "SP" already has a leader: "Hoeb"`)
+}
+
+func TestClubValidateLeaders(t *testing.T) {
+	c := &Club{Name: "AZA", Players: Players{{Name: "Fayeee", Role: RoleLeader}, {Name: "Richard", Role: RoleLeader}}}
+
+	assert.EqualError(t, c.Validate(), `invalid club "AZA": players can only have one leader. got ["Fayeee" "Richard"]`)
+}
+
+func TestRosterSortedByRole(t *testing.T) {
+	c := &Club{Name: "AZA", Players: Players{
+		{Name: "Fayeee", Might: 70000000, Role: RoleLeader},
+		{Name: "Richard", Might: 60000000, Role: RoleOfficer},
+		{Name: "LoverOnyx", Might: 80000000},
+		{Name: "Quinoa", Might: 850000, Role: "recruiter"},
+	}}
+
+	var b bytes.Buffer
+	assert.NoError(t, NewTemplates("").Execute(&b, "roster", c))
+	assert.Equal(t, "AZA - 4 players\n"+
+		"  Fayeee                 -    70m leader\n"+
+		"  Richard                -    60m officer\n"+
+		"  Quinoa                 -   850k recruiter\n"+
+		"  LoverOnyx              -    80m\n\n", b.String())
+}
@@ -0,0 +1,134 @@
+package witcharcana
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// UnassignedClub is the pseudo-club players are moved to when they're missing from an imported
+// roster and SyncUnassign is used.
+const UnassignedClub = "unassigned"
+
+// SyncMode determines what happens to players missing from an imported club roster.
+type SyncMode string
+
+const (
+	// SyncNone leaves players missing from an imported roster untouched.
+	SyncNone SyncMode = ""
+	// SyncInactive marks players missing from an imported roster as inactive.
+	SyncInactive SyncMode = "inactive"
+	// SyncUnassign moves players missing from an imported roster to the unassigned club.
+	SyncUnassign SyncMode = "unassign"
+	// SyncDelete removes players missing from an imported roster.
+	SyncDelete SyncMode = "delete"
+)
+
+// ParseSyncMode returns the SyncMode matching s.
+func ParseSyncMode(s string) (SyncMode, error) {
+	switch m := SyncMode(s); m {
+	case SyncNone, SyncInactive, SyncUnassign, SyncDelete:
+		return m, nil
+	}
+
+	return SyncNone, fmt.Errorf("unknown sync mode %q. options: %v",
+		s, []SyncMode{SyncInactive, SyncUnassign, SyncDelete})
+}
+
+// MissingPlayer records a player absent from their club's imported roster and what was done with
+// them.
+type MissingPlayer struct {
+	Name   string   `json:"name"`
+	Club   string   `json:"club"`
+	Action SyncMode `json:"action"`
+}
+
+// rosters returns the player names listed for each club in ps.
+func rosters(ps Players) map[string]map[string]bool {
+	rs := map[string]map[string]bool{}
+	for _, p := range ps {
+		if rs[p.Club] == nil {
+			rs[p.Club] = map[string]bool{}
+		}
+		rs[p.Club][p.Name] = true
+	}
+
+	return rs
+}
+
+// syncRosters reconciles each club found in rs against its imported roster, recording players that
+// aren't listed in chs and handling them according to mode.
+func syncRosters(ctx context.Context, cs *Clubs, rs map[string]map[string]bool, mode SyncMode, chs *ChangeSet) error {
+	if mode == SyncNone {
+		return nil
+	}
+
+	names := make([]string, 0, len(rs))
+	for n := range rs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		if n == UnassignedClub {
+			continue
+		}
+
+		c := club(cs.clubs, n)
+		if c == nil {
+			continue
+		}
+
+		var missing []*Player
+		for _, p := range c.Players {
+			if rs[n][p.Name] {
+				if mode == SyncInactive && p.Inactive {
+					p.Inactive = false
+					chs.Updated = append(chs.Updated, &PlayerChange{Name: p.Name, Club: n, Fields: []FieldChange{
+						{Field: "inactive", Old: "true", New: "false"},
+					}})
+				}
+				continue
+			}
+			if mode == SyncInactive && p.Inactive {
+				continue
+			}
+
+			missing = append(missing, p)
+		}
+
+		for _, p := range missing {
+			chs.Missing = append(chs.Missing, &MissingPlayer{Name: p.Name, Club: n, Action: mode})
+
+			if err := syncPlayer(ctx, cs, c, p, mode); err != nil {
+				return fmt.Errorf("syncing club %q: %w", n, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func syncPlayer(ctx context.Context, cs *Clubs, c *Club, p *Player, mode SyncMode) error {
+	switch mode {
+	case SyncInactive:
+		p.Inactive = true
+	case SyncUnassign:
+		maybeMakeClub(cs, UnassignedClub)
+		// players missing from the import weren't seen, so they're moved without being touched.
+		if _, err := movePlayer(ctx, cs, UnassignedClub, p.Name, false); err != nil {
+			return fmt.Errorf("unassigning player: %w", err)
+		}
+	case SyncDelete:
+		for i, cp := range c.Players {
+			if cp.Name == p.Name {
+				c.Players = append(c.Players[:i], c.Players[i+1:]...)
+				break
+			}
+		}
+	default:
+		return fmt.Errorf("unknown sync mode %q", mode)
+	}
+
+	return nil
+}
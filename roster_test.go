@@ -0,0 +1,117 @@
+package witcharcana
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSyncMode(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mode        string
+		expected    SyncMode
+		expectedErr error
+	}{
+		{name: "none", mode: "", expected: SyncNone},
+		{name: "inactive", mode: "inactive", expected: SyncInactive},
+		{name: "unassign", mode: "unassign", expected: SyncUnassign},
+		{name: "delete", mode: "delete", expected: SyncDelete},
+		{
+			name:        "unknown",
+			mode:        "archive",
+			expectedErr: fmt.Errorf(`unknown sync mode "archive". options: [inactive unassign delete]`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := ParseSyncMode(tc.mode)
+
+			assert.Equal(t, tc.expected, actual)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBulkUpdatePlayersSync(t *testing.T) {
+	testClubs := func() *Clubs {
+		return &Clubs{
+			clubs: map[string]*Club{
+				"CNT": {Name: "CNT", Players: []*Player{{Name: "Hoeb"}, {Name: "mxygem"}, {Name: "M4rs", Inactive: true}}},
+				"SP":  {Name: "SP", Players: []*Player{{Name: "Quinoa"}, {Name: "Jasmin"}}},
+			},
+		}
+	}
+	players := func() Players {
+		return Players{
+			{Name: "mxygem", Club: "CNT"},
+			{Name: "M4rs", Club: "CNT"},
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		mode            SyncMode
+		expected        *Clubs
+		expectedMissing []*MissingPlayer
+	}{
+		{
+			name:     "no sync",
+			mode:     SyncNone,
+			expected: testClubs(),
+		},
+		{
+			name: "mark inactive",
+			mode: SyncInactive,
+			expected: &Clubs{
+				clubs: map[string]*Club{
+					"CNT": {Name: "CNT", Players: []*Player{{Name: "Hoeb", Inactive: true}, {Name: "mxygem"}, {Name: "M4rs"}}},
+					"SP":  {Name: "SP", Players: []*Player{{Name: "Quinoa"}, {Name: "Jasmin"}}},
+				},
+			},
+			expectedMissing: []*MissingPlayer{{Name: "Hoeb", Club: "CNT", Action: SyncInactive}},
+		},
+		{
+			name: "unassign",
+			mode: SyncUnassign,
+			expected: &Clubs{
+				clubs: map[string]*Club{
+					"CNT":          {Name: "CNT", Players: []*Player{{Name: "mxygem"}, {Name: "M4rs", Inactive: true}}},
+					"SP":           {Name: "SP", Players: []*Player{{Name: "Quinoa"}, {Name: "Jasmin"}}},
+					UnassignedClub: {Name: UnassignedClub, Players: []*Player{{Name: "Hoeb"}}},
+				},
+			},
+			expectedMissing: []*MissingPlayer{{Name: "Hoeb", Club: "CNT", Action: SyncUnassign}},
+		},
+		{
+			name: "delete",
+			mode: SyncDelete,
+			expected: &Clubs{
+				clubs: map[string]*Club{
+					"CNT": {Name: "CNT", Players: []*Player{{Name: "mxygem"}, {Name: "M4rs", Inactive: true}}},
+					"SP":  {Name: "SP", Players: []*Player{{Name: "Quinoa"}, {Name: "Jasmin"}}},
+				},
+			},
+			expectedMissing: []*MissingPlayer{{Name: "Hoeb", Club: "CNT", Action: SyncDelete}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := testClubs()
+
+			chs, err := cs.BulkUpdatePlayers(context.Background(), players(), tc.mode)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, cs)
+			assert.Equal(t, tc.expectedMissing, chs.Missing)
+		})
+	}
+}
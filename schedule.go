@@ -0,0 +1,220 @@
+package witcharcana
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// MinScheduleEvery is the shortest time allowed between runs of a schedule, in minutes.
+	MinScheduleEvery = 60
+	// ScheduleStartLayout is the layout schedule start times are parsed in. Times are UTC.
+	ScheduleStartLayout = "2006-01-02T15:04"
+)
+
+// ScheduleKind is what a schedule posts when it runs.
+type ScheduleKind string
+
+const (
+	// ScheduleReminder posts its message, ie: a reminder that an event is starting.
+	ScheduleReminder ScheduleKind = "reminder"
+	// ScheduleRoster posts a summary of the roster of its club, or of every club, rendered with its
+	// template.
+	ScheduleRoster ScheduleKind = "roster"
+	// ScheduleStatsPing asks players to update their stats, with its message when set.
+	ScheduleStatsPing ScheduleKind = "stats-ping"
+)
+
+var scheduleKinds = []ScheduleKind{ScheduleReminder, ScheduleRoster, ScheduleStatsPing}
+
+// ParseScheduleKind returns the schedule kind named by s.
+func ParseScheduleKind(s string) (ScheduleKind, error) {
+	for _, k := range scheduleKinds {
+		if string(k) == s {
+			return k, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown schedule kind %q. options: %v", s, scheduleKinds)
+}
+
+// ParseEvery returns the minutes between runs named by s: hourly, daily, weekly or a number of
+// minutes.
+func ParseEvery(s string) (int, error) {
+	switch s {
+	case "hourly":
+		return 60, nil
+	case "daily":
+		return 24 * 60, nil
+	case "weekly":
+		return 7 * 24 * 60, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q. use hourly, daily, weekly or a number of minutes", s)
+	}
+
+	return n, nil
+}
+
+// Schedules is a collection of schedules.
+type Schedules []*Schedule
+
+// Schedule posts a message to a guild's channel every so often, starting from a given time.
+type Schedule struct {
+	ID      int          `json:"id"`
+	Guild   string       `json:"guild"`
+	Channel string       `json:"channel"`
+	Kind    ScheduleKind `json:"kind"`
+	// Start is the first run. Later runs follow every Every minutes after it.
+	Start time.Time `json:"start"`
+	Every int       `json:"every_minutes"`
+	// Message is posted by reminders and stats pings.
+	Message string `json:"message,omitempty"`
+	// Club limits roster summaries to a single club.
+	Club string `json:"club,omitempty"`
+	// Template renders roster summaries. Defaults to roster.
+	Template string `json:"template,omitempty"`
+}
+
+// Next returns the first run of the schedule after the time given.
+func (s *Schedule) Next(after time.Time) time.Time {
+	if after.Before(s.Start) {
+		return s.Start
+	}
+
+	every := time.Duration(s.Every) * time.Minute
+	if every <= 0 {
+		return time.Time{}
+	}
+	runs := after.Sub(s.Start)/every + 1
+
+	return s.Start.Add(runs * every)
+}
+
+// Due returns true when the schedule has a run after since and up to and including now.
+func (s *Schedule) Due(since, now time.Time) bool {
+	next := s.Next(since)
+	return !next.IsZero() && !next.After(now)
+}
+
+// Validate checks the schedule's guild, channel, kind, start, interval, message and club.
+func (s *Schedule) Validate() error {
+	return validationError("schedule", strconv.Itoa(s.ID), s.validate())
+}
+
+func (s *Schedule) validate() []*FieldError {
+	var fes []*FieldError
+	if s.Guild == "" {
+		fes = append(fes, &FieldError{Field: "guild", Reason: "required"})
+	}
+	if s.Channel == "" {
+		fes = append(fes, &FieldError{Field: "channel", Reason: "required"})
+	}
+	if _, err := ParseScheduleKind(string(s.Kind)); err != nil {
+		fes = append(fes, &FieldError{Field: "kind", Reason: fmt.Sprintf("must be one of %v. got %q", scheduleKinds, s.Kind)})
+	}
+	if s.Start.IsZero() {
+		fes = append(fes, &FieldError{Field: "start", Reason: "required"})
+	}
+	if s.Every < MinScheduleEvery {
+		fes = append(fes, &FieldError{
+			Field:  "every",
+			Reason: fmt.Sprintf("must be at least %d minutes. got %d", MinScheduleEvery, s.Every),
+		})
+	}
+
+	if s.Kind == ScheduleReminder && s.Message == "" {
+		fes = append(fes, &FieldError{Field: "message", Reason: "required for reminders"})
+	}
+	if n := len([]rune(s.Message)); n > MaxNotesLength {
+		fes = append(fes, &FieldError{
+			Field:  "message",
+			Reason: fmt.Sprintf("must be at most %d characters. got %d", MaxNotesLength, n),
+		})
+	}
+	if s.Club != "" {
+		fes = append(fes, validateName("club", s.Club)...)
+	}
+
+	return fes
+}
+
+// Guild returns the schedules of the given guild.
+func (ss Schedules) Guild(guild string) Schedules {
+	gs := Schedules{}
+	for _, s := range ss {
+		if s.Guild == guild {
+			gs = append(gs, s)
+		}
+	}
+
+	return gs
+}
+
+// Schedules returns the schedules of every guild ordered by id.
+func (cs *Clubs) Schedules(ctx context.Context) (Schedules, error) {
+	if cs.db == nil {
+		return cs.schedules, nil
+	}
+
+	ss, err := cs.db.Schedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting schedules: %w", err)
+	}
+	sort.Slice(ss, func(i, j int) bool { return ss[i].ID < ss[j].ID })
+
+	return ss, nil
+}
+
+// AddSchedule adds a schedule, giving it the next free id.
+func (cs *Clubs) AddSchedule(ctx context.Context, s *Schedule) error {
+	ss, err := cs.Schedules(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.ID = 1
+	if len(ss) > 0 {
+		s.ID = ss[len(ss)-1].ID + 1
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	if cs.db != nil {
+		if err := cs.db.CreateSchedule(ctx, s); err != nil {
+			return fmt.Errorf("creating schedule: %w", err)
+		}
+
+		return nil
+	}
+
+	cs.schedules = append(cs.schedules, s)
+
+	return nil
+}
+
+// RemoveSchedule removes the guild's schedule with the given id.
+func (cs *Clubs) RemoveSchedule(ctx context.Context, guild string, id int) error {
+	if cs.db != nil {
+		if err := cs.db.DeleteSchedule(ctx, guild, id); err != nil {
+			return fmt.Errorf("removing schedule: %w", err)
+		}
+
+		return nil
+	}
+
+	for i, s := range cs.schedules {
+		if s.ID == id && s.Guild == guild {
+			cs.schedules = append(cs.schedules[:i], cs.schedules[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %d", ErrScheduleNotFound, id)
+}
@@ -0,0 +1,118 @@
+package witcharcana
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testScheduleStart = time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)
+
+func TestParseEvery(t *testing.T) {
+	testCases := []struct {
+		in          string
+		expected    int
+		expectedErr error
+	}{
+		{in: "hourly", expected: 60},
+		{in: "weekly", expected: 10080},
+		{in: "90", expected: 90},
+		{in: "fortnightly", expectedErr: fmt.Errorf(`invalid interval "fortnightly". use hourly, daily, weekly or a number of minutes`)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			actual, err := ParseEvery(tc.in)
+
+			assert.Equal(t, tc.expected, actual)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	s := &Schedule{Guild: "g1", Channel: "c1", Kind: ScheduleStatsPing, Start: testScheduleStart, Every: 24 * 60}
+	day := 24 * time.Hour
+
+	testCases := []struct {
+		name     string
+		after    time.Time
+		expected time.Time
+	}{
+		{name: "before start", after: testScheduleStart.Add(-time.Hour), expected: testScheduleStart},
+		{name: "at start", after: testScheduleStart, expected: testScheduleStart.Add(day)},
+		{name: "between runs", after: testScheduleStart.Add(3*day + time.Minute), expected: testScheduleStart.Add(4 * day)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, s.Next(tc.after))
+		})
+	}
+
+	assert.True(t, s.Due(testScheduleStart.Add(-time.Minute), testScheduleStart))
+	assert.False(t, s.Due(testScheduleStart, testScheduleStart.Add(day-time.Minute)))
+	assert.True(t, s.Due(testScheduleStart, testScheduleStart.Add(3*day)), "missed runs are due once")
+}
+
+func TestScheduleValidate(t *testing.T) {
+	s := &Schedule{ID: 3, Kind: "nope", Every: 5}
+
+	assert.EqualError(t, s.Validate(), `invalid schedule "3": guild required; channel required; `+
+		`kind must be one of [reminder roster stats-ping]. got "nope"; start required; every must be at least 60 minutes. got 5`)
+
+	s = &Schedule{Guild: "g1", Channel: "c1", Kind: ScheduleReminder, Start: testScheduleStart, Every: 24 * 60}
+	assert.EqualError(t, s.Validate(), `invalid schedule "0": message required for reminders`)
+}
+
+func TestCsSchedules(t *testing.T) {
+	ctx := context.Background()
+	loc := filepath.Join(t.TempDir(), "clubs.json")
+	assert.NoError(t, Save(loc, map[string]*Club{}))
+
+	cs := &Clubs{}
+	assert.NoError(t, cs.LoadData(loc))
+
+	assert.NoError(t, cs.AddSchedule(ctx, &Schedule{Guild: "g1", Channel: "c1", Kind: ScheduleStatsPing, Start: testScheduleStart, Every: 24 * 60}))
+	roster := &Schedule{Guild: "g2", Channel: "c2", Kind: ScheduleRoster, Start: testScheduleStart, Every: 7 * 24 * 60, Club: "AZA"}
+	assert.NoError(t, cs.AddSchedule(ctx, roster))
+	assert.Equal(t, 2, roster.ID)
+
+	assert.NoError(t, cs.Save())
+	loaded := &Clubs{}
+	assert.NoError(t, loaded.LoadData(loc))
+	ss, err := loaded.Schedules(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, ss, 2)
+	assert.Equal(t, Schedules{roster}, ss.Guild("g2"))
+
+	err = loaded.RemoveSchedule(ctx, "g1", 2)
+	assert.True(t, errors.Is(err, ErrScheduleNotFound), "schedules of other guilds can't be removed")
+	assert.NoError(t, loaded.RemoveSchedule(ctx, "g2", 2))
+	ss, _ = loaded.Schedules(ctx)
+	assert.Len(t, ss, 1)
+
+	var ve *ValidationError
+	err = cs.AddSchedule(ctx, &Schedule{Guild: "g1"})
+	assert.True(t, errors.As(err, &ve))
+}
+
+func TestSchedulesTemplate(t *testing.T) {
+	s := &Schedule{ID: 1, Guild: "g1", Channel: "c1", Kind: ScheduleReminder, Start: testScheduleStart, Every: 24 * 60,
+		Message: "club war tonight!"}
+
+	var b bytes.Buffer
+	assert.NoError(t, NewTemplates("").Render(&b, Schedules{s}))
+	assert.Equal(t, "Schedules:\n"+
+		"    1 reminder   every 1440   min from 2026-10-19 18:00 UTC in <#c1>: club war tonight!\n", b.String())
+}
@@ -0,0 +1,194 @@
+package witcharcana
+
+import (
+	"context"
+	"fmt"
+)
+
+// ClubStats summarises a club's roster.
+type ClubStats struct {
+	Name    string `json:"name"`
+	Players int    `json:"players"`
+	Active  int    `json:"active"`
+	InHive  int    `json:"in_hive"`
+	// TotalMight is the might of every player while AverageMight only counts players with might
+	// recorded.
+	TotalMight   int64 `json:"total_might"`
+	AverageMight int64 `json:"average_might"`
+	// Levels counts the players at each level, leaving out players without a level recorded.
+	Levels map[int]int `json:"levels,omitempty"`
+	Spread *Spread     `json:"spread,omitempty"`
+}
+
+// Spread is the area covered by the players of a club with a location.
+type Spread struct {
+	Min     Location `json:"min"`
+	Max     Location `json:"max"`
+	Located int      `json:"located"`
+}
+
+// Width returns the distance between the westmost and eastmost players.
+func (s *Spread) Width() int {
+	return s.Max.X - s.Min.X
+}
+
+// Height returns the distance between the northmost and southmost players.
+func (s *Spread) Height() int {
+	return s.Max.Y - s.Min.Y
+}
+
+// Stats returns the stats of every club ordered by name. When clubName is provided only that club's
+// stats are returned.
+func (cs *Clubs) Stats(ctx context.Context, clubName string) ([]*ClubStats, error) {
+	clubs, err := selectClubs(ctx, cs, clubName)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]*ClubStats, 0, len(clubs))
+	for _, c := range clubs {
+		stats = append(stats, c.stats())
+	}
+
+	return stats, nil
+}
+
+func (c *Club) stats() *ClubStats {
+	st := &ClubStats{Name: c.Name, Players: len(c.Players)}
+
+	var withMight int64
+	for _, p := range c.Players {
+		if !p.Inactive {
+			st.Active++
+		}
+		if p.InHive {
+			st.InHive++
+		}
+		if p.Might > 0 {
+			st.TotalMight += p.Might
+			withMight++
+		}
+		if p.Level > 0 {
+			if st.Levels == nil {
+				st.Levels = map[int]int{}
+			}
+			st.Levels[p.Level]++
+		}
+		if p.Location != nil {
+			st.Spread = st.Spread.add(*p.Location)
+		}
+	}
+
+	if withMight > 0 {
+		st.AverageMight = st.TotalMight / withMight
+	}
+
+	return st
+}
+
+// add returns the spread grown to include l, starting a new spread when s is nil.
+func (s *Spread) add(l Location) *Spread {
+	if s == nil {
+		return &Spread{Min: l, Max: l, Located: 1}
+	}
+
+	s.Located++
+	if l.X < s.Min.X {
+		s.Min.X = l.X
+	}
+	if l.Y < s.Min.Y {
+		s.Min.Y = l.Y
+	}
+	if l.X > s.Max.X {
+		s.Max.X = l.X
+	}
+	if l.Y > s.Max.Y {
+		s.Max.Y = l.Y
+	}
+
+	return s
+}
+
+// RankBy is the player field a leaderboard is ranked by.
+type RankBy string
+
+const (
+	// RankByMight ranks players by might.
+	RankByMight RankBy = "might"
+	// RankByLevel ranks players by level.
+	RankByLevel RankBy = "level"
+)
+
+// ParseRankBy returns the leaderboard ranking named by s.
+func ParseRankBy(s string) (RankBy, error) {
+	switch b := RankBy(s); b {
+	case RankByMight, RankByLevel:
+		return b, nil
+	}
+
+	return "", fmt.Errorf("unknown leaderboard ranking %q. options: %v", s, []RankBy{RankByMight, RankByLevel})
+}
+
+func (b RankBy) value(p *Player) int64 {
+	if b == RankByLevel {
+		return int64(p.Level)
+	}
+
+	return p.Might
+}
+
+// Leaderboard ranks players from highest to lowest.
+type Leaderboard struct {
+	By      RankBy              `json:"by"`
+	Club    string              `json:"club,omitempty"`
+	Entries []*LeaderboardEntry `json:"entries"`
+}
+
+// LeaderboardEntry is a player and their place on a leaderboard. Players with the same value share a
+// rank.
+type LeaderboardEntry struct {
+	Rank int `json:"rank"`
+	*Player
+}
+
+// Leaderboard returns the top players of every club, or only clubName's when provided, ranked by the
+// given field. Every player is ranked when top isn't positive. Inactive players and players without
+// a value recorded for the field are left out.
+func (cs *Clubs) Leaderboard(ctx context.Context, by RankBy, top int, clubName string) (*Leaderboard, error) {
+	clubs, err := selectClubs(ctx, cs, clubName)
+	if err != nil {
+		return nil, err
+	}
+
+	var ps Players
+	for _, c := range clubs {
+		for _, p := range c.Players {
+			if p.Inactive || by.value(p) <= 0 {
+				continue
+			}
+
+			rp := *p
+			rp.Club = c.Name
+			ps = append(ps, &rp)
+		}
+	}
+
+	ps, err = sortPlayers(string(by), ps)
+	if err != nil {
+		return nil, fmt.Errorf("ranking players: %w", err)
+	}
+	if top > 0 && len(ps) > top {
+		ps = ps[:top]
+	}
+
+	lb := &Leaderboard{By: by, Club: clubName, Entries: make([]*LeaderboardEntry, 0, len(ps))}
+	for i, p := range ps {
+		rank := i + 1
+		if i > 0 && by.value(p) == by.value(ps[i-1]) {
+			rank = lb.Entries[i-1].Rank
+		}
+		lb.Entries = append(lb.Entries, &LeaderboardEntry{Rank: rank, Player: p})
+	}
+
+	return lb, nil
+}
@@ -0,0 +1,205 @@
+package witcharcana
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCsStats(t *testing.T) {
+	testCases := []struct {
+		name        string
+		clubs       Clubs
+		clubName    string
+		expected    []*ClubStats
+		expectedErr error
+	}{
+		{
+			name: "all clubs",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{
+						{Name: "Fayeee", Level: 18, Might: 70265122, Location: &Location{X: 303, Y: 733}},
+						{Name: "Richard", Level: 12, Might: 90000000, Inactive: true},
+						{Name: "LoverOnyx", Level: 18, Might: 51848883, Location: &Location{X: 310, Y: 720}, InHive: true},
+						{Name: "Quinoa"},
+					}},
+					"CNT": {Name: "CNT", Players: Players{
+						{Name: "mxygem", Level: 19, Might: 51848883},
+					}},
+					"SP": {Name: "SP"},
+				},
+			},
+			expected: []*ClubStats{
+				{
+					Name: "AZA", Players: 4, Active: 3, InHive: 1,
+					TotalMight: 212114005, AverageMight: 70704668,
+					Levels: map[int]int{12: 1, 18: 2},
+					Spread: &Spread{Min: Location{X: 303, Y: 720}, Max: Location{X: 310, Y: 733}, Located: 2},
+				},
+				{Name: "CNT", Players: 1, Active: 1, TotalMight: 51848883, AverageMight: 51848883, Levels: map[int]int{19: 1}},
+				{Name: "SP"},
+			},
+		},
+		{
+			name: "single club",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee", Level: 18, Might: 70265122}}},
+					"CNT": {Name: "CNT", Players: Players{{Name: "mxygem", Level: 19, Might: 51848883}}},
+				},
+			},
+			clubName: "CNT",
+			expected: []*ClubStats{
+				{Name: "CNT", Players: 1, Active: 1, TotalMight: 51848883, AverageMight: 51848883, Levels: map[int]int{19: 1}},
+			},
+		},
+		{
+			name: "club not found",
+			clubs: Clubs{
+				clubs: map[string]*Club{"SP": {Name: "SP"}},
+			},
+			clubName:    "404",
+			expectedErr: fmt.Errorf(`getting club: club not found: "404"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.clubs.Stats(context.Background(), tc.clubName)
+
+			assert.Equal(t, tc.expected, actual)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	sp := &Spread{Min: Location{X: 303, Y: 720}, Max: Location{X: 310, Y: 733}}
+	assert.Equal(t, 7, sp.Width())
+	assert.Equal(t, 13, sp.Height())
+}
+
+func TestCsLeaderboard(t *testing.T) {
+	testCases := []struct {
+		name        string
+		clubs       Clubs
+		by          RankBy
+		top         int
+		clubName    string
+		expected    []*LeaderboardEntry
+		expectedErr error
+	}{
+		{
+			name: "might with shared ranks",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{
+						{Name: "Fayeee", Level: 18, Might: 70265122, Location: &Location{X: 303, Y: 733}},
+						{Name: "Richard", Level: 12, Might: 90000000, Inactive: true},
+						{Name: "LoverOnyx", Level: 18, Might: 51848883, Location: &Location{X: 310, Y: 720}, InHive: true},
+						{Name: "Quinoa"},
+					}},
+					"CNT": {Name: "CNT", Players: Players{
+						{Name: "mxygem", Level: 19, Might: 51848883},
+					}},
+					"SP": {Name: "SP"},
+				},
+			},
+			by: RankByMight,
+			expected: []*LeaderboardEntry{
+				{Rank: 1, Player: &Player{Name: "Fayeee", Club: "AZA", Level: 18, Might: 70265122, Location: &Location{X: 303, Y: 733}}},
+				{Rank: 2, Player: &Player{Name: "LoverOnyx", Club: "AZA", Level: 18, Might: 51848883, Location: &Location{X: 310, Y: 720}, InHive: true}},
+				{Rank: 2, Player: &Player{Name: "mxygem", Club: "CNT", Level: 19, Might: 51848883}},
+			},
+		},
+		{
+			name: "top level",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{
+						{Name: "Fayeee", Level: 18, Might: 70265122, Location: &Location{X: 303, Y: 733}},
+						{Name: "Richard", Level: 12, Might: 90000000, Inactive: true},
+						{Name: "LoverOnyx", Level: 18, Might: 51848883, Location: &Location{X: 310, Y: 720}, InHive: true},
+						{Name: "Quinoa"},
+					}},
+					"CNT": {Name: "CNT", Players: Players{
+						{Name: "mxygem", Level: 19, Might: 51848883},
+					}},
+					"SP": {Name: "SP"},
+				},
+			},
+			by:  RankByLevel,
+			top: 2,
+			expected: []*LeaderboardEntry{
+				{Rank: 1, Player: &Player{Name: "mxygem", Club: "CNT", Level: 19, Might: 51848883}},
+				{Rank: 2, Player: &Player{Name: "Fayeee", Club: "AZA", Level: 18, Might: 70265122, Location: &Location{X: 303, Y: 733}}},
+			},
+		},
+		{
+			name: "single club",
+			clubs: Clubs{
+				clubs: map[string]*Club{
+					"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee", Level: 18, Might: 70265122}}},
+					"CNT": {Name: "CNT", Players: Players{{Name: "mxygem", Level: 19, Might: 51848883}}},
+				},
+			},
+			by:       RankByMight,
+			top:      1,
+			clubName: "CNT",
+			expected: []*LeaderboardEntry{
+				{Rank: 1, Player: &Player{Name: "mxygem", Club: "CNT", Level: 19, Might: 51848883}},
+			},
+		},
+		{
+			name: "no ranked players",
+			clubs: Clubs{
+				clubs: map[string]*Club{"SP": {Name: "SP", Players: Players{{Name: "Hoeb"}}}},
+			},
+			by:       RankByLevel,
+			clubName: "SP",
+			expected: []*LeaderboardEntry{},
+		},
+		{
+			name: "club not found",
+			clubs: Clubs{
+				clubs: map[string]*Club{"SP": {Name: "SP"}},
+			},
+			by:          RankByMight,
+			clubName:    "404",
+			expectedErr: fmt.Errorf(`getting club: club not found: "404"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.clubs.Leaderboard(context.Background(), tc.by, tc.top, tc.clubName)
+
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, &Leaderboard{By: tc.by, Club: tc.clubName, Entries: tc.expected}, actual)
+			// stored players are left without their club set
+			for _, c := range tc.clubs.clubs {
+				for _, p := range c.Players {
+					assert.Empty(t, p.Club)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRankBy(t *testing.T) {
+	b, err := ParseRankBy("level")
+	assert.NoError(t, err)
+	assert.Equal(t, RankByLevel, b)
+
+	_, err = ParseRankBy("name")
+	assert.EqualError(t, err, `unknown leaderboard ranking "name". options: [might level]`)
+}
@@ -0,0 +1,256 @@
+package witcharcana
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxTagLength is the longest tag or custom role allowed, in characters.
+	MaxTagLength = 24
+	// MaxNotesLength is the longest notes allowed on a club or player, in characters.
+	MaxNotesLength = 500
+)
+
+// Tags is a sorted set of lower case labels, ie: farm or kvk-captain.
+type Tags []string
+
+// ParseTags parses tags separated by commas, semicolons or spaces, ie: "farm, kvk-captain".
+func ParseTags(s string) (Tags, error) {
+	fs := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' || unicode.IsSpace(r) })
+
+	ts := make(Tags, 0, len(fs))
+	for _, f := range fs {
+		t := strings.ToLower(f)
+		if fe := validateTag("tags", t); fe != nil {
+			return nil, fe
+		}
+		ts = append(ts, t)
+	}
+
+	return ts.add(), nil
+}
+
+// String returns the tags separated by commas.
+func (ts Tags) String() string {
+	return strings.Join(ts, ", ")
+}
+
+// Has returns true when every tag given is in the set.
+func (ts Tags) Has(tags ...string) bool {
+	for _, t := range tags {
+		if !ts.has(t) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (ts Tags) has(tag string) bool {
+	for _, t := range ts {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// add returns a new sorted set of the tags with more added.
+func (ts Tags) add(more ...string) Tags {
+	seen := map[string]bool{}
+	nts := Tags{}
+	for _, t := range append(append([]string{}, ts...), more...) {
+		if !seen[t] {
+			seen[t] = true
+			nts = append(nts, t)
+		}
+	}
+	sort.Strings(nts)
+
+	return nts
+}
+
+// remove returns a new set of the tags without less.
+func (ts Tags) remove(less ...string) Tags {
+	nts := Tags{}
+	for _, t := range ts {
+		if !Tags(less).has(t) {
+			nts = append(nts, t)
+		}
+	}
+
+	return nts
+}
+
+// equal returns true when both sets hold the same tags.
+func (ts Tags) equal(o Tags) bool {
+	return len(ts) == len(o) && ts.Has(o...)
+}
+
+// validateTag checks a tag is present and a valid label.
+func validateTag(field, t string) *FieldError {
+	if t == "" {
+		return &FieldError{Field: field, Reason: "cannot contain empty tags"}
+	}
+
+	return validateLabel(field, "tag", t)
+}
+
+// validateLabel checks a tag or custom role is not too long and only uses lower case letters, digits
+// and the punctuation - _ so it survives being split from csv cells and bot commands. Reasons name
+// the label with its kind when given.
+func validateLabel(field, kind, s string) *FieldError {
+	label := fmt.Sprintf("%q", s)
+	if kind != "" {
+		label = kind + " " + label
+	}
+
+	if n := len([]rune(s)); n > MaxTagLength {
+		return &FieldError{Field: field, Reason: fmt.Sprintf("%s must be at most %d characters. got %d", label, MaxTagLength, n)}
+	}
+	for _, r := range s {
+		if (unicode.IsLetter(r) && !unicode.IsUpper(r)) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			continue
+		}
+
+		return &FieldError{Field: field, Reason: fmt.Sprintf("%s cannot contain %q", label, r)}
+	}
+
+	return nil
+}
+
+// validateTagged checks the tags and notes of a club or player.
+func validateTagged(prefix string, ts Tags, notes string) []*FieldError {
+	var fes []*FieldError
+	for _, t := range ts {
+		if fe := validateTag(prefix+"tags", t); fe != nil {
+			fes = append(fes, fe)
+		}
+	}
+	if n := len([]rune(notes)); n > MaxNotesLength {
+		fes = append(fes, &FieldError{
+			Field:  prefix + "notes",
+			Reason: fmt.Sprintf("must be at most %d characters. got %d", MaxNotesLength, n),
+		})
+	}
+
+	return fes
+}
+
+// Tagged returns a copy of the club holding only the players with every tag given.
+func (c *Club) Tagged(tags ...string) *Club {
+	cc := *c
+	cc.Players = Players{}
+	for _, p := range c.Players {
+		if p.Tags.Has(tags...) {
+			cc.Players = append(cc.Players, p)
+		}
+	}
+
+	return &cc
+}
+
+// TaggedPlayers returns the players of every club, or only clubName's when provided, with every tag
+// given, ordered by club name.
+func (cs *Clubs) TaggedPlayers(ctx context.Context, clubName string, tags ...string) (Players, error) {
+	clubs, err := selectClubs(ctx, cs, clubName)
+	if err != nil {
+		return nil, err
+	}
+
+	ps := Players{}
+	for _, c := range clubs {
+		for _, p := range c.Tagged(tags...).Players {
+			tp := *p
+			tp.Club = c.Name
+			ps = append(ps, &tp)
+		}
+	}
+
+	return ps, nil
+}
+
+// TagPlayer adds tags to the named player.
+func (cs *Clubs) TagPlayer(ctx context.Context, name string, tags ...string) (*Player, error) {
+	return cs.editPlayer(ctx, name, func(p *Player) { p.Tags = p.Tags.add(tags...) })
+}
+
+// UntagPlayer removes tags from the named player.
+func (cs *Clubs) UntagPlayer(ctx context.Context, name string, tags ...string) (*Player, error) {
+	return cs.editPlayer(ctx, name, func(p *Player) { p.Tags = p.Tags.remove(tags...) })
+}
+
+// SetPlayerNotes replaces the notes of the named player. Empty notes clear them.
+func (cs *Clubs) SetPlayerNotes(ctx context.Context, name, notes string) (*Player, error) {
+	return cs.editPlayer(ctx, name, func(p *Player) { p.Notes = notes })
+}
+
+// editPlayer applies edit to the named player, keeping the player as they were when the result is
+// invalid.
+func (cs *Clubs) editPlayer(ctx context.Context, name string, edit func(p *Player)) (*Player, error) {
+	n, p, c, err := playerClub(ctx, cs, name)
+	if err != nil {
+		return nil, err
+	}
+
+	edit(p)
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	cs.touch(p, false, false)
+
+	sp := *p
+	sp.Club = ""
+	c.Players[n] = &sp
+
+	if cs.db != nil {
+		if err := cs.db.Update(ctx, c); err != nil {
+			return nil, fmt.Errorf("updating db: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+// TagClub adds tags to the named club.
+func (cs *Clubs) TagClub(ctx context.Context, name string, tags ...string) (*Club, error) {
+	return cs.editClub(ctx, name, func(c *Club) { c.Tags = c.Tags.add(tags...) })
+}
+
+// UntagClub removes tags from the named club.
+func (cs *Clubs) UntagClub(ctx context.Context, name string, tags ...string) (*Club, error) {
+	return cs.editClub(ctx, name, func(c *Club) { c.Tags = c.Tags.remove(tags...) })
+}
+
+// SetClubNotes replaces the notes of the named club. Empty notes clear them.
+func (cs *Clubs) SetClubNotes(ctx context.Context, name, notes string) (*Club, error) {
+	return cs.editClub(ctx, name, func(c *Club) { c.Notes = notes })
+}
+
+// editClub applies edit to the named club, leaving the club as it was when the result is invalid.
+func (cs *Clubs) editClub(ctx context.Context, name string, edit func(c *Club)) (*Club, error) {
+	c, err := cs.Club(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("getting club: %w", err)
+	}
+
+	ec := *c
+	edit(&ec)
+	if fes := validateTagged("", ec.Tags, ec.Notes); len(fes) > 0 {
+		return nil, validationError("club", ec.Name, fes)
+	}
+	c.Tags, c.Notes = ec.Tags, ec.Notes
+
+	if cs.db != nil {
+		if err := cs.db.Update(ctx, c); err != nil {
+			return nil, fmt.Errorf("updating db: %w", err)
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,174 @@
+package witcharcana
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTags(t *testing.T) {
+	testCases := []struct {
+		in          string
+		expected    Tags
+		expectedErr error
+	}{
+		{in: "farm", expected: Tags{"farm"}},
+		{in: "KvK-Captain, farm;farm  vacation_11-2", expected: Tags{"farm", "kvk-captain", "vacation_11-2"}},
+		{in: " , ", expected: Tags{}},
+		{in: "farm,on:vacation", expectedErr: fmt.Errorf(`tags tag "on:vacation" cannot contain ':'`)},
+		{in: "abcdefghijklmnopqrstuvwxyz", expectedErr: fmt.Errorf(`tags tag "abcdefghijklmnopqrstuvwxyz" must be at most 24 characters. got 26`)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			actual, err := ParseTags(tc.in)
+
+			assert.Equal(t, tc.expected, actual)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTags(t *testing.T) {
+	ts := Tags{"farm", "kvk-captain"}
+
+	assert.True(t, ts.Has("kvk-captain", "farm"))
+	assert.True(t, ts.Has())
+	assert.False(t, ts.Has("farm", "vacation"))
+	assert.Equal(t, Tags{"farm", "kvk-captain", "vacation"}, ts.add("vacation", "farm"))
+	assert.Equal(t, Tags{"kvk-captain"}, ts.remove("farm", "vacation"))
+	assert.Equal(t, Tags{"farm", "kvk-captain"}, ts, "sets are never changed in place")
+	assert.Equal(t, "farm, kvk-captain", ts.String())
+}
+
+func TestCsTaggedPlayers(t *testing.T) {
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"AZA": {Name: "AZA", Players: Players{
+				{Name: "Fayeee", Tags: Tags{"farm", "kvk-captain"}},
+				{Name: "Richard", Tags: Tags{"farm"}},
+				{Name: "LoverOnyx"},
+			}},
+			"SP": {Name: "SP", Players: Players{{Name: "Quinoa", Tags: Tags{"kvk-captain"}}}},
+		},
+	}
+
+	ps, err := cs.TaggedPlayers(context.Background(), "", "kvk-captain")
+	assert.NoError(t, err)
+	assert.Equal(t, Players{
+		{Name: "Fayeee", Club: "AZA", Tags: Tags{"farm", "kvk-captain"}},
+		{Name: "Quinoa", Club: "SP", Tags: Tags{"kvk-captain"}},
+	}, ps)
+
+	ps, err = cs.TaggedPlayers(context.Background(), "AZA", "farm", "kvk-captain")
+	assert.NoError(t, err)
+	assert.Equal(t, Players{{Name: "Fayeee", Club: "AZA", Tags: Tags{"farm", "kvk-captain"}}}, ps)
+
+	_, err = cs.TaggedPlayers(context.Background(), "404", "farm")
+	assert.EqualError(t, err, `getting club: club not found: "404"`)
+
+	c := cs.clubs["AZA"].Tagged("farm")
+	assert.Equal(t, []string{"Fayeee", "Richard"}, []string{c.Players[0].Name, c.Players[1].Name})
+}
+
+func TestCsEditPlayerTagsAndNotes(t *testing.T) {
+	ctx := context.Background()
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"AZA": {Name: "AZA", Players: Players{
+				{Name: "Fayeee", Tags: Tags{"farm", "kvk-captain"}},
+				{Name: "Richard", Tags: Tags{"farm"}},
+				{Name: "LoverOnyx"},
+			}},
+		},
+	}
+
+	p, err := cs.TagPlayer(ctx, "LoverOnyx", "vacation", "farm")
+	assert.NoError(t, err)
+	assert.Equal(t, &Player{Name: "LoverOnyx", Club: "AZA", Tags: Tags{"farm", "vacation"}}, p)
+
+	_, err = cs.UntagPlayer(ctx, "Fayeee", "farm")
+	assert.NoError(t, err)
+
+	_, err = cs.SetPlayerNotes(ctx, "Fayeee", "on vacation until 11/2")
+	assert.NoError(t, err)
+
+	assert.Equal(t, Players{
+		{Name: "Fayeee", Tags: Tags{"kvk-captain"}, Notes: "on vacation until 11/2"},
+		{Name: "Richard", Tags: Tags{"farm"}},
+		{Name: "LoverOnyx", Tags: Tags{"farm", "vacation"}},
+	}, cs.clubs["AZA"].Players)
+
+	_, err = cs.TagPlayer(ctx, "Fayeee", "Not Valid")
+	var ve *ValidationError
+	assert.True(t, errors.As(err, &ve))
+	assert.Equal(t, Tags{"kvk-captain"}, cs.clubs["AZA"].Players[0].Tags, "invalid tags aren't stored")
+
+	_, err = cs.TagPlayer(ctx, "Hoeb", "farm")
+	assert.True(t, errors.Is(err, ErrPlayerNotFound))
+}
+
+func TestCsEditClubTagsAndNotes(t *testing.T) {
+	ctx := context.Background()
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"SP": {Name: "SP", Players: Players{{Name: "Quinoa", Tags: Tags{"kvk-captain"}}}},
+		},
+	}
+
+	c, err := cs.TagClub(ctx, "SP", "feeder", "kvk")
+	assert.NoError(t, err)
+	assert.Equal(t, Tags{"feeder", "kvk"}, c.Tags)
+
+	c, err = cs.UntagClub(ctx, "SP", "kvk")
+	assert.NoError(t, err)
+	assert.Equal(t, Tags{"feeder"}, c.Tags)
+
+	_, err = cs.SetClubNotes(ctx, "SP", string(make([]byte, MaxNotesLength+1)))
+	assert.EqualError(t, err, `invalid club "SP": notes must be at most 500 characters. got 501`)
+
+	c, err = cs.SetClubNotes(ctx, "SP", "feeds AZA")
+	assert.NoError(t, err)
+	assert.Equal(t, "feeds AZA", cs.clubs["SP"].Notes)
+
+	var b bytes.Buffer
+	assert.NoError(t, NewTemplates("").Render(&b, c))
+	assert.Equal(t, "Club:\n"+
+		"    Name: SP\n"+
+		"    Tags: feeder\n"+
+		"    Notes: feeds AZA\n"+
+		"    Players:\n"+
+		"        Name: Quinoa\n"+
+		"            Tags: kvk-captain\n", b.String())
+}
+
+func TestBulkUpdateReplacesTags(t *testing.T) {
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"AZA": {Name: "AZA", Players: Players{
+				{Name: "Fayeee", Tags: Tags{"farm", "kvk-captain"}},
+				{Name: "Richard", Tags: Tags{"farm"}},
+			}},
+		},
+	}
+
+	chs, err := cs.BulkUpdatePlayers(context.Background(), Players{
+		{Name: "Fayeee", Club: "AZA", Tags: Tags{"vacation"}},
+		{Name: "Richard", Club: "AZA"},
+	}, SyncNone)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*PlayerChange{
+		{Name: "Fayeee", Club: "AZA", Fields: []FieldChange{{Field: "tags", Old: "farm, kvk-captain", New: "vacation"}}},
+	}, chs.Updated)
+	assert.Equal(t, Tags{"vacation"}, cs.clubs["AZA"].Players[0].Tags)
+	assert.Equal(t, Tags{"farm"}, cs.clubs["AZA"].Players[1].Tags, "tags are kept when none are given")
+}
@@ -0,0 +1,311 @@
+package witcharcana
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+const templateExt = ".tmpl"
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// Templates renders data with named text templates. The built in templates are embedded in the
+// binary and a template of the same name in the override dir is used in place of the built in one.
+// Files starting with _ hold partials, named with define, that every template can use.
+type Templates struct {
+	dir string
+}
+
+// NewTemplates returns a pointer to a new Templates object using templates in dir, if given, over the
+// built in ones.
+func NewTemplates(dir string) *Templates {
+	return &Templates{dir: dir}
+}
+
+// Names returns the names of every template available, sorted.
+func (t *Templates) Names() ([]string, error) {
+	names := map[string]bool{}
+
+	bs, err := fs.Glob(builtinTemplates, "templates/*"+templateExt)
+	if err != nil {
+		return nil, fmt.Errorf("listing built in templates: %w", err)
+	}
+	for _, b := range bs {
+		if n := path.Base(b); !isPartial(n) {
+			names[strings.TrimSuffix(n, templateExt)] = true
+		}
+	}
+
+	if t.dir != "" {
+		ds, err := filepath.Glob(filepath.Join(t.dir, "*"+templateExt))
+		if err != nil {
+			return nil, fmt.Errorf("listing templates in %q: %w", t.dir, err)
+		}
+		for _, d := range ds {
+			if n := filepath.Base(d); !isPartial(n) {
+				names[strings.TrimSuffix(n, templateExt)] = true
+			}
+		}
+	}
+
+	ns := make([]string, 0, len(names))
+	for n := range names {
+		ns = append(ns, n)
+	}
+	sort.Strings(ns)
+
+	return ns, nil
+}
+
+// Render renders data to w with the template named for its type by TemplateFor.
+func (t *Templates) Render(w io.Writer, data any) error {
+	name, err := TemplateFor(data)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, name, data)
+}
+
+// TemplateFor returns the name of the template used to render data by default.
+func TemplateFor(data any) (string, error) {
+	switch data.(type) {
+	case map[string]*Club:
+		return "clubs", nil
+	case *Club:
+		return "club", nil
+	case *Player:
+		return "player", nil
+	case Players:
+		return "players", nil
+	case Prospects:
+		return "prospects", nil
+	case InactivityReport:
+		return "inactive", nil
+	case []*ClubStats:
+		return "stats", nil
+	case *Leaderboard:
+		return "leaderboard", nil
+	case Events:
+		return "events", nil
+	case *Event:
+		return "event", nil
+	case Participation:
+		return "participation", nil
+	case Schedules:
+		return "schedules", nil
+	default:
+		return "", fmt.Errorf("no template for %T", data)
+	}
+}
+
+// Execute renders data with the named template, ie: roster, to w.
+func (t *Templates) Execute(w io.Writer, name string, data any) error {
+	tmpl, err := t.template(name)
+	if err != nil {
+		return err
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("executing template %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// template parses the named template from the override dir if it's there and otherwise from the
+// built in templates.
+func (t *Templates) template(name string) (*template.Template, error) {
+	if isPartial(name) {
+		return nil, fmt.Errorf("template %q is a partial", name)
+	}
+	file := name + templateExt
+
+	var src []byte
+	var err error
+	if t.dir != "" {
+		src, err = os.ReadFile(filepath.Join(t.dir, file))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("reading template %q: %w", name, err)
+		}
+	}
+	if src == nil {
+		src, err = builtinTemplates.ReadFile(path.Join("templates", file))
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		names, _ := t.Names()
+		return nil, fmt.Errorf("unknown template %q. options: %v", name, names)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading template %q: %w", name, err)
+	}
+
+	tmpl, err := t.partials(template.New(file).Funcs(templateFuncs))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmpl.Parse(string(src)); err != nil {
+		return nil, fmt.Errorf("parsing template %q: %w", name, err)
+	}
+
+	return tmpl, nil
+}
+
+// partials parses the built in partials into tmpl followed by those in the override dir, whose
+// definitions replace built in ones of the same name.
+func (t *Templates) partials(tmpl *template.Template) (*template.Template, error) {
+	bs, err := fs.Glob(builtinTemplates, "templates/_*"+templateExt)
+	if err != nil {
+		return nil, fmt.Errorf("listing built in partials: %w", err)
+	}
+	for _, b := range bs {
+		src, err := builtinTemplates.ReadFile(b)
+		if err != nil {
+			return nil, fmt.Errorf("reading partials %q: %w", b, err)
+		}
+		if _, err := tmpl.Parse(string(src)); err != nil {
+			return nil, fmt.Errorf("parsing partials %q: %w", b, err)
+		}
+	}
+
+	if t.dir == "" {
+		return tmpl, nil
+	}
+
+	ds, err := filepath.Glob(filepath.Join(t.dir, "_*"+templateExt))
+	if err != nil {
+		return nil, fmt.Errorf("listing partials in %q: %w", t.dir, err)
+	}
+	for _, d := range ds {
+		src, err := os.ReadFile(d)
+		if err != nil {
+			return nil, fmt.Errorf("reading partials %q: %w", d, err)
+		}
+		if _, err := tmpl.Parse(string(src)); err != nil {
+			return nil, fmt.Errorf("parsing partials %q: %w", d, err)
+		}
+	}
+
+	return tmpl, nil
+}
+
+func isPartial(name string) bool {
+	return strings.HasPrefix(name, "_")
+}
+
+// templateFuncs are the helper functions available to every template.
+var templateFuncs = template.FuncMap{
+	// might formats might compactly, ie: 70.2m.
+	"might": FormatMight,
+	// number formats a number with thousands separators, ie: 70,265,122.
+	"number": formatNumber,
+	// pad pads a value with spaces on the right to width characters.
+	"pad": func(width int, v any) string {
+		s := fmt.Sprint(v)
+		return s + padding(width, s)
+	},
+	// padLeft pads a value with spaces on the left to width characters.
+	"padLeft": func(width int, v any) string {
+		s := fmt.Sprint(v)
+		return padding(width, s) + s
+	},
+	// sortBy returns players sorted by name, by level or might from highest to lowest, or by role from
+	// the leader down to members.
+	"sortBy": sortPlayers,
+	// clubs returns a single club or map of clubs as a list sorted by name.
+	"clubs": clubList,
+}
+
+func clubList(v any) ([]*Club, error) {
+	switch cs := v.(type) {
+	case *Club:
+		return []*Club{cs}, nil
+	case []*Club:
+		return cs, nil
+	case map[string]*Club:
+		l := make([]*Club, 0, len(cs))
+		for _, c := range cs {
+			l = append(l, c)
+		}
+		sort.Slice(l, func(i, j int) bool { return l[i].Name < l[j].Name })
+
+		return l, nil
+	default:
+		return nil, fmt.Errorf("clubs: unsupported value of type %T", v)
+	}
+}
+
+// padding returns the spaces needed to pad s to width characters.
+func padding(width int, s string) string {
+	n := width - utf8.RuneCountInString(s)
+	if n <= 0 {
+		return ""
+	}
+
+	return strings.Repeat(" ", n)
+}
+
+func formatNumber(v any) (string, error) {
+	var n int64
+	switch i := v.(type) {
+	case int:
+		n = int64(i)
+	case int64:
+		n = i
+	default:
+		return "", fmt.Errorf("number: unsupported value %v of type %T", v, v)
+	}
+
+	s := strconv.FormatInt(n, 10)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+
+	if neg {
+		return "-" + b.String(), nil
+	}
+
+	return b.String(), nil
+}
+
+// sortPlayers returns a sorted copy of ps, leaving ps in its stored order. Ties keep stored order.
+func sortPlayers(field string, ps Players) (Players, error) {
+	var less func(a, b *Player) bool
+	switch field {
+	case "name":
+		less = func(a, b *Player) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case "level":
+		less = func(a, b *Player) bool { return a.Level > b.Level }
+	case "might":
+		less = func(a, b *Player) bool { return a.Might > b.Might }
+	case "role":
+		less = func(a, b *Player) bool { return a.Role.rank() < b.Role.rank() }
+	default:
+		return nil, fmt.Errorf("unknown sort field %q. options: [name level might role]", field)
+	}
+
+	sorted := make(Players, len(ps))
+	copy(sorted, ps)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+
+	return sorted, nil
+}
@@ -0,0 +1,226 @@
+package witcharcana
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplatesExecute(t *testing.T) {
+	clubs := map[string]*Club{
+		"AZA": {Name: "AZA", Location: &Location{X: 300, Y: 730}, Players: Players{
+			{Name: "Richard", Inactive: true},
+			{Name: "Fayeee", Level: 18, Might: 70265122, Location: &Location{X: 303, Y: 733}},
+			{Name: "LoverOnyx", Level: 9, Might: 850000, InHive: true},
+		}},
+	}
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "roster.tmpl"), []byte(`{{ range . }}{{ .Name }}{{ end }}`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "names.tmpl"), []byte(`{{ range sortBy "name" . }}{{ pad 10 .Name }}|{{ end }}`), 0644))
+
+	testCases := []struct {
+		name        string
+		dir         string
+		template    string
+		data        any
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "built in roster",
+			template: "roster",
+			data:     clubs,
+			expected: "AZA (300:730) - 3 players\n" +
+				"  Fayeee                18  70.2m\n" +
+				"  LoverOnyx              9   850k hive\n" +
+				"  Richard                -      0 inactive\n\n",
+		},
+		{
+			name:     "built in roster for one club",
+			template: "roster",
+			data:     &Club{Name: "SP", Players: Players{{Name: "Quinoa", Level: 16}}},
+			expected: "SP - 1 players\n" +
+				"  Quinoa                16      0\n\n",
+		},
+		{
+			name:     "built in roster without clubs",
+			template: "roster",
+			data:     map[string]*Club{},
+			expected: "No club data found!\n",
+		},
+		{
+			name:     "override",
+			dir:      dir,
+			template: "roster",
+			data:     clubs,
+			expected: "AZA",
+		},
+		{
+			name:     "added in override dir",
+			dir:      dir,
+			template: "names",
+			data:     clubs["AZA"].Players,
+			expected: "Fayeee    |LoverOnyx |Richard   |",
+		},
+		{
+			name:        "unknown",
+			dir:         dir,
+			template:    "nope",
+			expectedErr: `unknown template "nope". options: [club clubs event events inactive leaderboard names participation player players prospects roster schedules stats]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+
+			err := NewTemplates(tc.dir).Execute(&b, tc.template, tc.data)
+
+			assert.Equal(t, tc.expected, b.String())
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	// stored order is left alone by sorting
+	assert.Equal(t, "Richard", clubs["AZA"].Players[0].Name)
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	for v, expected := range map[int64]string{0: "0", 999: "999", 1000: "1k", 1260: "1.2k", 850000: "850k",
+		999960: "999.9k", 51848883: "51.8m", 70265122: "70.2m", 1500000000: "1.5b"} {
+		assert.Equal(t, expected, FormatMight(v), v)
+	}
+
+	for v, expected := range map[int64]string{0: "0", 999: "999", 1000: "1,000", -1234567: "-1,234,567", 70265122: "70,265,122"} {
+		actual, err := formatNumber(v)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	_, err := sortPlayers("club", nil)
+	assert.EqualError(t, err, `unknown sort field "club". options: [name level might role]`)
+}
+
+func TestTemplatesRender(t *testing.T) {
+	fayeee := &Player{Name: "Fayeee", Club: "AZA", Level: 18, Might: 70265122, Location: &Location{X: 303, Y: 733}}
+
+	testCases := []struct {
+		name        string
+		data        any
+		expected    string
+		expectedErr string
+	}{
+		{
+			name: "club",
+			data: &Club{Name: "AZA", Location: &Location{X: 300, Y: 730}, Players: Players{{Name: "Richard", Inactive: true}}},
+			expected: "Club:\n" +
+				"    Name: AZA\n" +
+				"    Loc: 300, 730\n" +
+				"    Players:\n" +
+				"        Name: Richard\n" +
+				"            Inactive: true\n",
+		},
+		{
+			name: "player",
+			data: fayeee,
+			expected: "Player:\n" +
+				"        Name: Fayeee\n" +
+				"            Club: AZA\n" +
+				"            Loc: 303, 733\n" +
+				"            Level: 18\n" +
+				"            Might: 70.2m\n" +
+				"            In Hive: false\n",
+		},
+		{
+			name: "players",
+			data: Players{fayeee, {Name: "Quinoa", Club: "SP", InHive: true}},
+			expected: "Players:\n" +
+				"        Name: Fayeee\n" +
+				"            Club: AZA\n" +
+				"            Loc: 303, 733\n" +
+				"            Level: 18\n" +
+				"            Might: 70.2m\n" +
+				"            In Hive: false\n" +
+				"        Name: Quinoa\n" +
+				"            Club: SP\n" +
+				"            In Hive: true\n",
+		},
+		{
+			name:     "no players",
+			data:     Players{},
+			expected: "No players found!\n",
+		},
+		{
+			name: "stats",
+			data: []*ClubStats{
+				{
+					Name: "AZA", Players: 3, Active: 2, InHive: 1, TotalMight: 122113005, AverageMight: 61056502,
+					Levels: map[int]int{18: 2, 9: 1},
+					Spread: &Spread{Min: Location{X: 303, Y: 720}, Max: Location{X: 310, Y: 733}, Located: 2},
+				},
+				{Name: "SP"},
+			},
+			expected: "AZA - 3 players, 2 active, 1 in hive\n" +
+				"  might: 122.1m total, 61m average\n" +
+				"  levels: 9x1 18x2\n" +
+				"  spread: 303:720 to 310:733 (7x13, 2 located)\n\n" +
+				"SP - 0 players, 0 active, 0 in hive\n" +
+				"  might: 0 total, 0 average\n\n",
+		},
+		{
+			name: "leaderboard",
+			data: &Leaderboard{By: RankByMight, Entries: []*LeaderboardEntry{
+				{Rank: 1, Player: fayeee},
+				{Rank: 2, Player: &Player{Name: "Quinoa", Club: "SP", Might: 850000}},
+			}},
+			expected: "Top 2 by might\n" +
+				"  1. Fayeee               AZA    18  70.2m\n" +
+				"  2. Quinoa               SP      -   850k\n",
+		},
+		{
+			name:     "empty leaderboard",
+			data:     &Leaderboard{By: RankByLevel, Club: "SP", Entries: []*LeaderboardEntry{}},
+			expected: "No ranked players found!\n",
+		},
+		{
+			name:        "unsupported",
+			data:        42,
+			expectedErr: "no template for int",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+
+			err := NewTemplates("").Render(&b, tc.data)
+
+			assert.Equal(t, tc.expected, b.String())
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTemplatesPartialOverride(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "_mine.tmpl"), []byte(`{{ define "player" }}{{ .Name }} ({{ might .Might }}){{ end }}`), 0644))
+
+	var b bytes.Buffer
+	assert.NoError(t, NewTemplates(dir).Render(&b, &Player{Name: "Fayeee", Might: 70265122}))
+	assert.Equal(t, "Player:Fayeee (70.2m)\n", b.String())
+
+	err := NewTemplates(dir).Execute(&b, "_mine", nil)
+	assert.EqualError(t, err, `template "_mine" is a partial`)
+}
@@ -0,0 +1,205 @@
+package witcharcana
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxNameLength is the longest club or player name allowed, in characters.
+	MaxNameLength = 32
+	// MapSize is the largest coordinate on either axis of the map. Coordinates start at 1.
+	MapSize = 1000
+	// MaxLevel is the highest level a player can have. A level of 0 means it isn't known.
+	MaxLevel = 100
+)
+
+// FieldError describes a single invalid field.
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Reason
+}
+
+// ValidationError holds every problem found while validating a club, player or location.
+type ValidationError struct {
+	// Kind is what was validated: club, player or location.
+	Kind   string
+	Name   string
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	fs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		fs[i] = f.Error()
+	}
+
+	if e.Name == "" {
+		return fmt.Sprintf("invalid %s: %s", e.Kind, strings.Join(fs, "; "))
+	}
+
+	return fmt.Sprintf("invalid %s %q: %s", e.Kind, e.Name, strings.Join(fs, "; "))
+}
+
+// Unwrap returns the individual field errors.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, f := range e.Fields {
+		errs[i] = f
+	}
+
+	return errs
+}
+
+// Validate checks the location is within the bounds of the map.
+func (l *Location) Validate() error {
+	return validationError("location", "", l.validate(""))
+}
+
+func (l *Location) validate(prefix string) []*FieldError {
+	var fes []*FieldError
+	for _, c := range []struct {
+		field string
+		val   int
+	}{
+		{field: "x", val: l.X},
+		{field: "y", val: l.Y},
+	} {
+		if c.val < 1 || c.val > MapSize {
+			fes = append(fes, &FieldError{
+				Field:  prefix + c.field,
+				Reason: fmt.Sprintf("must be between 1 and %d. got %d", MapSize, c.val),
+			})
+		}
+	}
+
+	return fes
+}
+
+// Validate checks the club's name, location, capacity, recruitment state, tags, notes, that it has at
+// most one leader and each of its players and prospects.
+func (c *Club) Validate() error {
+	return validationError("club", c.Name, c.validate())
+}
+
+func (c *Club) validate() []*FieldError {
+	fes := validateName("name", c.Name)
+	if c.Location != nil {
+		fes = append(fes, c.Location.validate("location.")...)
+	}
+
+	if c.Capacity < 0 {
+		fes = append(fes, &FieldError{
+			Field:  "capacity",
+			Reason: fmt.Sprintf("cannot be negative. got %d", c.Capacity),
+		})
+	}
+	if c.Recruitment != "" {
+		if _, err := ParseRecruitment(string(c.Recruitment)); err != nil {
+			fes = append(fes, &FieldError{
+				Field:  "recruitment",
+				Reason: fmt.Sprintf("must be one of %v. got %q", recruitments, c.Recruitment),
+			})
+		}
+	}
+
+	var leaders []string
+	for _, p := range c.Players {
+		fes = append(fes, p.validate(fmt.Sprintf("player %q ", p.Name))...)
+		if p.Role == RoleLeader {
+			leaders = append(leaders, p.Name)
+		}
+	}
+	if len(leaders) > 1 {
+		fes = append(fes, &FieldError{
+			Field:  "players",
+			Reason: fmt.Sprintf("can only have one leader. got %q", leaders),
+		})
+	}
+	fes = append(fes, validateTagged("", c.Tags, c.Notes)...)
+
+	for _, pr := range c.Prospects {
+		fes = append(fes, pr.validate(fmt.Sprintf("prospect %q ", pr.Name))...)
+	}
+
+	return fes
+}
+
+// Validate checks the player's name, club name, level, might, location, role, tags and notes.
+func (p *Player) Validate() error {
+	return validationError("player", p.Name, p.validate(""))
+}
+
+func (p *Player) validate(prefix string) []*FieldError {
+	fes := validateName(prefix+"name", p.Name)
+	// players only hold the name of their club while outside of it.
+	if p.Club != "" {
+		fes = append(fes, validateName(prefix+"club", p.Club)...)
+	}
+
+	if p.Level < 0 || p.Level > MaxLevel {
+		fes = append(fes, &FieldError{
+			Field:  prefix + "level",
+			Reason: fmt.Sprintf("must be between 0 and %d. got %d", MaxLevel, p.Level),
+		})
+	}
+	if p.Might < 0 {
+		fes = append(fes, &FieldError{
+			Field:  prefix + "might",
+			Reason: fmt.Sprintf("cannot be negative. got %d", p.Might),
+		})
+	}
+	if p.Location != nil {
+		fes = append(fes, p.Location.validate(prefix+"location.")...)
+	}
+	if fe := validateRole(prefix+"role", p.Role); fe != nil {
+		fes = append(fes, fe)
+	}
+	fes = append(fes, validateTagged(prefix, p.Tags, p.Notes)...)
+
+	return fes
+}
+
+// validateName checks a club or player name is present, not too long and only uses letters, digits,
+// spaces and the punctuation ' _ - . found in player names.
+func validateName(field, name string) []*FieldError {
+	if name == "" {
+		return []*FieldError{{Field: field, Reason: "required"}}
+	}
+
+	var fes []*FieldError
+	if n := utf8.RuneCountInString(name); n > MaxNameLength {
+		fes = append(fes, &FieldError{
+			Field:  field,
+			Reason: fmt.Sprintf("must be at most %d characters. got %d", MaxNameLength, n),
+		})
+	}
+	if strings.TrimSpace(name) != name {
+		fes = append(fes, &FieldError{Field: field, Reason: "cannot start or end with spaces"})
+	}
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || strings.ContainsRune(" '_-.", r) {
+			continue
+		}
+
+		fes = append(fes, &FieldError{Field: field, Reason: fmt.Sprintf("cannot contain %q", r)})
+		break
+	}
+
+	return fes
+}
+
+// validationError returns a ValidationError for any field errors found, or nil when there are none.
+func validationError(kind, name string, fes []*FieldError) error {
+	if len(fes) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Kind: kind, Name: name, Fields: fes}
+}
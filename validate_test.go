@@ -0,0 +1,126 @@
+package witcharcana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlayerValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		player      *Player
+		expectedErr error
+	}{
+		{
+			name:   "valid",
+			player: &Player{Name: "_ScarletRose_", Club: "404", Level: 18, Might: 70265122, Location: &Location{X: 303, Y: 733}},
+		},
+		{
+			name:   "spaces and unicode",
+			player: &Player{Name: "Lady Luña.x"},
+		},
+		{
+			name:        "no name",
+			player:      &Player{},
+			expectedErr: fmt.Errorf("invalid player: name required"),
+		},
+		{
+			name:        "name too long",
+			player:      &Player{Name: strings.Repeat("a", 33)},
+			expectedErr: fmt.Errorf(`invalid player "%s": name must be at most 32 characters. got 33`, strings.Repeat("a", 33)),
+		},
+		{
+			name:        "name padded with spaces",
+			player:      &Player{Name: " Fayeee"},
+			expectedErr: fmt.Errorf(`invalid player " Fayeee": name cannot start or end with spaces`),
+		},
+		{
+			name:        "invalid characters",
+			player:      &Player{Name: "Fay<eee>"},
+			expectedErr: fmt.Errorf(`invalid player "Fay<eee>": name cannot contain '<'`),
+		},
+		{
+			name:   "all problems reported",
+			player: &Player{Name: "Hoeb", Club: "C!T", Level: 101, Might: -1, Location: &Location{X: 0, Y: 1001}},
+			expectedErr: fmt.Errorf(`invalid player "Hoeb": club cannot contain '!'; ` +
+				`level must be between 0 and 100. got 101; might cannot be negative. got -1; ` +
+				`location.x must be between 1 and 1000. got 0; location.y must be between 1 and 1000. got 1001`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.player.Validate()
+
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClubValidate(t *testing.T) {
+	c := &Club{
+		Name:     "AZA",
+		Location: &Location{X: -1, Y: 733},
+		Players:  Players{{Name: "Fayeee", Level: -3}, {Name: "Richard"}},
+	}
+
+	err := c.Validate()
+	assert.EqualError(t, err, `invalid club "AZA": location.x must be between 1 and 1000. got -1; player "Fayeee" level must be between 0 and 100. got -3`)
+
+	var ve *ValidationError
+	assert.True(t, errors.As(err, &ve))
+	assert.Equal(t, "club", ve.Kind)
+	assert.Len(t, ve.Fields, 2)
+
+	var fe *FieldError
+	assert.True(t, errors.As(err, &fe))
+	assert.Equal(t, "location.x", fe.Field)
+
+	assert.NoError(t, (&Club{Name: "404"}).Validate())
+
+	err = (&Club{Name: "SP", Capacity: -1, Recruitment: "maybe", Prospects: Prospects{{Name: "Quinoa", Might: -5}}}).Validate()
+	assert.EqualError(t, err, `invalid club "SP": capacity cannot be negative. got -1; `+
+		`recruitment must be one of [open invite closed]. got "maybe"; prospect "Quinoa" might cannot be negative. got -5`)
+}
+
+func TestLocationValidate(t *testing.T) {
+	assert.NoError(t, (&Location{X: 1, Y: 1000}).Validate())
+	assert.EqualError(t, (&Location{X: 1001, Y: 5}).Validate(), "invalid location: x must be between 1 and 1000. got 1001")
+}
+
+func TestValidationApplied(t *testing.T) {
+	cs := &Clubs{
+		clubs: map[string]*Club{
+			"AZA": {Name: "AZA", Players: Players{{Name: "Fayeee", Level: 18}}},
+		},
+	}
+
+	_, err := cs.CreatePlayer(context.Background(), "AZA", &Player{Name: "Rich@rd"})
+	assert.EqualError(t, err, `creating player: invalid player "Rich@rd": name cannot contain '@'`)
+
+	_, err = cs.UpdatePlayer(context.Background(), &Player{Name: "Fayeee", Location: &Location{X: 2000, Y: 1}})
+	assert.EqualError(t, err, `invalid player "Fayeee": location.x must be between 1 and 1000. got 2000`)
+
+	_, err = cs.BulkUpdatePlayers(context.Background(), Players{
+		{Name: "Fayeee", Club: "AZA", Level: 19},
+		{Name: "Quinoa", Club: "SP", Might: -5},
+	}, SyncNone)
+	assert.EqualError(t, err, `bulk update: invalid player "Quinoa": might cannot be negative. got -5`)
+
+	// nothing is changed by rejected updates
+	assert.Equal(t, Players{{Name: "Fayeee", Level: 18}}, cs.clubs["AZA"].Players)
+	assert.Len(t, cs.clubs, 1)
+
+	err = cs.CreateClub(context.Background(), &Club{Name: "S P "})
+	var ve *ValidationError
+	assert.True(t, errors.As(err, &ve))
+}
@@ -1,7 +1,14 @@
 package witcharcana
 
+import "fmt"
+
 // Location stores an X and Y coordinate representing the location of a club or player.
 type Location struct {
-	X int `json:"x" csv:"x"`
-	Y int `json:"y" csv:"y"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// String returns the location in its "x:y" form.
+func (l Location) String() string {
+	return fmt.Sprintf("%d:%d", l.X, l.Y)
 }
@@ -0,0 +1,134 @@
+package witcharcana
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// xlsxFiles are the static parts of a single sheet workbook.
+var xlsxFiles = []struct {
+	name string
+	body string
+}{
+	{
+		name: "[Content_Types].xml",
+		body: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`,
+	},
+	{
+		name: "_rels/.rels",
+		body: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`,
+	},
+	{
+		name: "xl/workbook.xml",
+		body: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="players" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`,
+	},
+	{
+		name: "xl/_rels/workbook.xml.rels",
+		body: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`,
+	},
+}
+
+// writeXLSXExport writes rows as a minimal single sheet xlsx workbook.
+func writeXLSXExport(w io.Writer, rows []*ExportRow) error {
+	zw := zip.NewWriter(w)
+
+	for _, f := range xlsxFiles {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", f.name, err)
+		}
+		if _, err := io.WriteString(fw, f.body); err != nil {
+			return fmt.Errorf("writing %s: %w", f.name, err)
+		}
+	}
+
+	fw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return fmt.Errorf("creating sheet: %w", err)
+	}
+	if _, err := fw.Write(xlsxSheet(rows)); err != nil {
+		return fmt.Errorf("writing sheet: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("closing workbook: %w", err)
+	}
+
+	return nil
+}
+
+func xlsxSheet(rows []*ExportRow) []byte {
+	var b bytes.Buffer
+
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	xlsxRow(&b, 1, exportHeader, nil)
+	for i, r := range rows {
+		// level and might are written as numbers, in_hive as a boolean.
+		xlsxRow(&b, i+2, r.cells(), map[int]string{2: "n", 3: "n", 5: "b"})
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+
+	return b.Bytes()
+}
+
+// xlsxRow writes a single row of cells. Cells are written as inline strings unless their column has
+// another type in types. Empty cells are skipped.
+func xlsxRow(b *bytes.Buffer, n int, cells []string, types map[int]string) {
+	fmt.Fprintf(b, `<row r="%d">`, n)
+
+	for i, v := range cells {
+		if v == "" {
+			continue
+		}
+
+		ref := xlsxColumn(i) + strconv.Itoa(n)
+		switch types[i] {
+		case "n":
+			fmt.Fprintf(b, `<c r="%s"><v>%s</v></c>`, ref, v)
+		case "b":
+			bv := "0"
+			if v == "true" {
+				bv = "1"
+			}
+			fmt.Fprintf(b, `<c r="%s" t="b"><v>%s</v></c>`, ref, bv)
+		default:
+			fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t>`, ref)
+			_ = xml.EscapeText(b, []byte(v))
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+
+	b.WriteString(`</row>`)
+}
+
+// xlsxColumn returns the spreadsheet column letters for a zero based column index.
+func xlsxColumn(i int) string {
+	var s string
+	for i++; i > 0; i = (i - 1) / 26 {
+		s = string(rune('A'+(i-1)%26)) + s
+	}
+
+	return s
+}